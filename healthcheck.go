@@ -0,0 +1,765 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/checkers"
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// newHealthChecker creates the health checker configured by the environment
+// variables starting with prefix, e.g. GSLB_PRIMARY_CHECK. The variable named
+// prefix itself holds the check target, prefix_TYPE selects the checker. host
+// is the GSLB host name, used as default for Host headers and TLS server names.
+// webhook receives pushed health, it is nil if no receiver is configured.
+func newHealthChecker(prefix, host string, webhook *checkers.WebhookReceiver) (gslb.HealthChecker, error) {
+	target := os.Getenv(prefix)
+	if target == "" {
+		return nil, fmt.Errorf("%s is not set", prefix)
+	}
+
+	var err error
+
+	switch checkType := os.Getenv(prefix + "_TYPE"); checkType {
+	case "", "http":
+		skipTLSVerify, _ := strconv.ParseBool(os.Getenv(prefix + "_SKIP_TLS_VERIFY"))
+		chk := checkers.NewSimpleHTTPChecker(target, skipTLSVerify)
+		chk.Method = os.Getenv(prefix + "_METHOD")
+		chk.Headers = envHeaders(prefix + "_HEADER_")
+		chk.Host = envDefault(prefix+"_HOST_HEADER", host)
+		chk.ServerName = envDefault(prefix+"_TLS_SERVER_NAME", host)
+
+		if chk.TLSFiles, chk.MinTLSVersion, err = envTLSOptions(prefix); err != nil {
+			return nil, err
+		}
+
+		if chk.StatusCodes, err = checkers.ParseStatusCodes(os.Getenv(prefix + "_STATUS_CODES")); err != nil {
+			return nil, fmt.Errorf("parsing %s_STATUS_CODES: %w", prefix, err)
+		}
+
+		if chk.BodyAssertions, err = httpBodyAssertions(prefix); err != nil {
+			return nil, err
+		}
+
+		if chk.Latency, err = envLatencyThreshold(prefix); err != nil {
+			return nil, err
+		}
+
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "tcp":
+		chk := checkers.NewTCPChecker(target, 5*time.Second)
+
+		if chk.Send, err = envUnquote(prefix + "_TCP_SEND"); err != nil {
+			return nil, err
+		}
+		if chk.Expect, err = envUnquote(prefix + "_TCP_EXPECT"); err != nil {
+			return nil, err
+		}
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "dns":
+		// Default to the standard DNS port if none is given
+		target = withDefaultPort(target, "53")
+
+		name := os.Getenv(prefix + "_DNS_NAME")
+		if name == "" {
+			return nil, fmt.Errorf("%s_DNS_NAME is not set", prefix)
+		}
+
+		qtype := os.Getenv(prefix + "_DNS_TYPE")
+		if qtype == "" {
+			qtype = "A"
+		}
+
+		chk := checkers.NewDNSChecker(target, name, qtype)
+		chk.TCP, _ = strconv.ParseBool(os.Getenv(prefix + "_DNS_TCP"))
+		chk.RCodes = envList(prefix + "_DNS_RCODES")
+		chk.Expect = os.Getenv(prefix + "_DNS_EXPECT")
+
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "grpc":
+		useTLS, _ := strconv.ParseBool(os.Getenv(prefix + "_GRPC_TLS"))
+		skipTLSVerify, _ := strconv.ParseBool(os.Getenv(prefix + "_SKIP_TLS_VERIFY"))
+
+		chk := checkers.NewGRPCChecker(target, useTLS, skipTLSVerify)
+		chk.Service = os.Getenv(prefix + "_GRPC_SERVICE")
+
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "exec":
+		args, err := splitArgs(os.Getenv(prefix + "_EXEC_ARGS"))
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s_EXEC_ARGS: %w", prefix, err)
+		}
+
+		chk := checkers.NewExecChecker(target, args...)
+		chk.Env = envVars(prefix + "_EXEC_ENV_")
+
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "tls":
+		chk := checkers.NewTLSChecker(target, envDefault(prefix+"_TLS_SERVER_NAME", host))
+		chk.Hostname = host
+
+		if chk.TLSFiles, _, err = envTLSOptions(prefix); err != nil {
+			return nil, err
+		}
+		if chk.ExpiryWarning, err = envDays(prefix + "_TLS_EXPIRY_WARNING_DAYS"); err != nil {
+			return nil, err
+		}
+		if chk.ExpiryCritical, err = envDays(prefix + "_TLS_EXPIRY_CRITICAL_DAYS"); err != nil {
+			return nil, err
+		}
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "postgres":
+		chk := checkers.NewPostgresChecker(withDefaultPort(target, "5432"), os.Getenv(prefix+"_DB_NAME"), os.Getenv(prefix+"_DB_USER"))
+		chk.PasswordFile = os.Getenv(prefix + "_DB_PASSWORD_FILE")
+		chk.SSLMode = os.Getenv(prefix + "_POSTGRES_SSLMODE")
+		chk.RequirePrimary, _ = strconv.ParseBool(os.Getenv(prefix + "_DB_REQUIRE_PRIMARY"))
+
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "mysql":
+		chk := checkers.NewMySQLChecker(withDefaultPort(target, "3306"), os.Getenv(prefix+"_DB_NAME"), os.Getenv(prefix+"_DB_USER"))
+		chk.PasswordFile = os.Getenv(prefix + "_DB_PASSWORD_FILE")
+
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "redis":
+		chk := checkers.NewRedisChecker(withDefaultPort(target, "6379"))
+		chk.User = os.Getenv(prefix + "_DB_USER")
+		chk.PasswordFile = os.Getenv(prefix + "_DB_PASSWORD_FILE")
+		chk.RequireMaster, _ = strconv.ParseBool(os.Getenv(prefix + "_DB_REQUIRE_PRIMARY"))
+
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "kubernetes":
+		cfg, err := envKubeConfig(prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		chk := checkers.NewKubernetesChecker(cfg, checkers.KubernetesKind(envDefault(prefix+"_K8S_KIND", string(checkers.KubernetesEndpoints))))
+
+		// The target is [namespace/]service or a node label selector
+		if chk.Kind == checkers.KubernetesNodes {
+			chk.Selector = target
+		} else if ns, svc, ok := strings.Cut(target, "/"); ok {
+			chk.Namespace, chk.Service = ns, svc
+		} else {
+			chk.Service = target
+		}
+
+		if chk.MinReady, err = envInt(prefix+"_K8S_MIN_READY", chk.MinReady); err != nil {
+			return nil, err
+		}
+		if err := chk.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s Kubernetes check: %w", prefix, err)
+		}
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "haproxy":
+		chk := checkers.NewHAProxyChecker(target, os.Getenv(prefix+"_HAPROXY_BACKEND"))
+
+		if chk.MinUp, err = envInt(prefix+"_HAPROXY_MIN_UP", chk.MinUp); err != nil {
+			return nil, err
+		}
+		if err := chk.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s HAProxy check: %w", prefix, err)
+		}
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "docker":
+		dockerHost := envDefault(prefix+"_DOCKER_HOST", envDefault("DOCKER_HOST", checkers.DefaultDockerHost))
+		chk := checkers.NewDockerChecker(dockerHost, target)
+
+		if chk.TLSFiles, _, err = envTLSOptions(prefix); err != nil {
+			return nil, err
+		}
+		if err := chk.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s Docker check: %w", prefix, err)
+		}
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "smtp", "imap", "ldap":
+		return newProtocolChecker(checkType, prefix, target, host)
+	case "prometheus":
+		chk := checkers.NewPrometheusChecker(target, os.Getenv(prefix+"_PROM_QUERY"))
+		chk.Headers = envHeaders(prefix + "_HEADER_")
+		chk.SkipTLSVerify, _ = strconv.ParseBool(os.Getenv(prefix + "_SKIP_TLS_VERIFY"))
+		chk.ServerName = os.Getenv(prefix + "_TLS_SERVER_NAME")
+
+		if mode := os.Getenv(prefix + "_PROM_MODE"); mode != "" {
+			chk.Mode = checkers.PrometheusMode(mode)
+		}
+
+		if chk.Mode == checkers.PrometheusThreshold {
+			if chk.Op, chk.Threshold, err = checkers.ParsePrometheusCondition(os.Getenv(prefix + "_PROM_CONDITION")); err != nil {
+				return nil, fmt.Errorf("parsing %s_PROM_CONDITION: %w", prefix, err)
+			}
+			chk.NoData = checkers.PrometheusNoData(os.Getenv(prefix + "_PROM_NO_DATA"))
+		}
+
+		if chk.TLSFiles, chk.MinTLSVersion, err = envTLSOptions(prefix); err != nil {
+			return nil, err
+		}
+		if chk.Retry, err = envRetryPolicy(prefix, chk.Retry); err != nil {
+			return nil, err
+		}
+		if err := chk.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s Prometheus check: %w", prefix, err)
+		}
+
+		return chk, nil
+	case "webhook":
+		if webhook == nil {
+			return nil, fmt.Errorf("%s_TYPE webhook requires GSLB_WEBHOOK_LISTEN", prefix)
+		}
+
+		chk := webhook.Checker(target)
+
+		if chk.UnhealthyLabels, err = envLabels(prefix + "_WEBHOOK_UNHEALTHY_LABELS"); err != nil {
+			return nil, err
+		}
+		if chk.DegradedLabels, err = envLabels(prefix + "_WEBHOOK_DEGRADED_LABELS"); err != nil {
+			return nil, err
+		}
+
+		return chk, nil
+	case "composite":
+		return newCompositeChecker(prefix, target, host, webhook)
+	default:
+		return nil, fmt.Errorf("unsupported %s_TYPE %q", prefix, checkType)
+	}
+}
+
+// newCompositeChecker creates a composite checker of the comma separated
+// member names in target. Every member is configured like a health checker
+// with the prefix extended by _MEMBER_ and its name, e.g.
+// GSLB_PRIMARY_CHECK_MEMBER_API, so member names cannot collide with the
+// options of the composite check itself.
+func newCompositeChecker(prefix, target, host string, webhook *checkers.WebhookReceiver) (*checkers.CompositeChecker, error) {
+	chk := &checkers.CompositeChecker{}
+
+	switch require := os.Getenv(prefix + "_REQUIRE"); require {
+	case "", "all":
+		chk.Mode = checkers.CompositeAll
+	case "any":
+		chk.Mode = checkers.CompositeAny
+	default:
+		quorum, err := strconv.Atoi(require)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s_REQUIRE: %w", prefix, err)
+		}
+
+		chk.Mode = checkers.CompositeQuorum
+		chk.Quorum = quorum
+	}
+
+	for _, name := range strings.Split(target, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		memberPrefix := prefix + "_MEMBER_" + name
+
+		member, err := newHealthChecker(memberPrefix, host, webhook)
+		if err != nil {
+			return nil, err
+		}
+
+		weight, err := envInt(memberPrefix+"_WEIGHT", 1)
+		if err != nil {
+			return nil, err
+		}
+
+		chk.Members = append(chk.Members, checkers.CompositeMember{
+			Name:    strings.ToLower(name),
+			Checker: member,
+			Weight:  weight,
+		})
+	}
+
+	if err := chk.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s composite check: %w", prefix, err)
+	}
+
+	return chk, nil
+}
+
+// httpBodyAssertions collects the response body assertions configured for an
+// HTTP health check.
+func httpBodyAssertions(prefix string) ([]checkers.BodyAssertion, error) {
+	var assertions []checkers.BodyAssertion
+
+	if s := os.Getenv(prefix + "_BODY_CONTAINS"); s != "" {
+		assertions = append(assertions, checkers.ContainsAssertion(s))
+	}
+
+	if s := os.Getenv(prefix + "_BODY_REGEX"); s != "" {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s_BODY_REGEX: %w", prefix, err)
+		}
+
+		assertions = append(assertions, checkers.RegexAssertion{Regexp: re})
+	}
+
+	for _, expr := range envList(prefix + "_BODY_JSON") {
+		a, err := checkers.ParseJSONPathAssertion(expr)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s_BODY_JSON: %w", prefix, err)
+		}
+
+		assertions = append(assertions, a)
+	}
+
+	return assertions, nil
+}
+
+// newProtocolChecker creates the SMTP, IMAP or LDAP checker of checkType,
+// which share their TLS options. The standard port of the protocol is used if
+// the target has none.
+func newProtocolChecker(checkType, prefix, target, host string) (gslb.HealthChecker, error) {
+	mode, err := checkers.ParseTLSMode(os.Getenv(prefix + "_TLS_MODE"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s_TLS_MODE: %w", prefix, err)
+	}
+
+	skipVerify, _ := strconv.ParseBool(os.Getenv(prefix + "_SKIP_TLS_VERIFY"))
+	serverName := envDefault(prefix+"_TLS_SERVER_NAME", host)
+
+	files, minVersion, err := envTLSOptions(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	port := map[string][2]string{
+		"smtp": {"25", "465"},
+		"imap": {"143", "993"},
+		"ldap": {"389", "636"},
+	}[checkType]
+
+	address := withDefaultPort(target, port[0])
+	if mode == checkers.TLSImplicit {
+		address = withDefaultPort(target, port[1])
+	}
+
+	var chk gslb.HealthChecker
+	var retry *checkers.RetryPolicy
+
+	switch checkType {
+	case "smtp":
+		c := checkers.NewSMTPChecker(address)
+		c.HeloName = os.Getenv(prefix + "_SMTP_HELO")
+		c.TLSMode, c.SkipTLSVerify, c.ServerName, c.TLSFiles, c.MinTLSVersion = mode, skipVerify, serverName, files, minVersion
+		chk, retry = c, &c.Retry
+	case "imap":
+		c := checkers.NewIMAPChecker(address)
+		c.TLSMode, c.SkipTLSVerify, c.ServerName, c.TLSFiles, c.MinTLSVersion = mode, skipVerify, serverName, files, minVersion
+		chk, retry = c, &c.Retry
+	default:
+		c := checkers.NewLDAPChecker(address)
+		c.BindDN = os.Getenv(prefix + "_LDAP_BIND_DN")
+		c.PasswordFile = os.Getenv(prefix + "_LDAP_PASSWORD_FILE")
+		c.TLSMode, c.SkipTLSVerify, c.ServerName, c.TLSFiles, c.MinTLSVersion = mode, skipVerify, serverName, files, minVersion
+		chk, retry = c, &c.Retry
+	}
+
+	if *retry, err = envRetryPolicy(prefix, *retry); err != nil {
+		return nil, err
+	}
+
+	return chk, nil
+}
+
+// envKubeConfig loads the kubeconfig file of a Kubernetes check, or the in
+// cluster config if none is set.
+func envKubeConfig(prefix string) (*checkers.KubeConfig, error) {
+	path := envDefault(prefix+"_K8S_KUBECONFIG", os.Getenv("KUBECONFIG"))
+	if path == "" {
+		return checkers.InClusterKubeConfig()
+	}
+
+	cfg, err := checkers.LoadKubeConfig(path, os.Getenv(prefix+"_K8S_CONTEXT"))
+	if err != nil {
+		return nil, fmt.Errorf("loading %s_K8S_KUBECONFIG: %w", prefix, err)
+	}
+
+	return cfg, nil
+}
+
+// envTLSOptions reads the CA bundle, client certificate and minimum TLS
+// version configured for a health check. The files are nil if none are set.
+func envTLSOptions(prefix string) (*checkers.TLSFiles, uint16, error) {
+	minVersion, err := checkers.ParseTLSVersion(os.Getenv(prefix + "_TLS_MIN_VERSION"))
+	if err != nil {
+		return nil, 0, fmt.Errorf("parsing %s_TLS_MIN_VERSION: %w", prefix, err)
+	}
+
+	caFile := os.Getenv(prefix + "_TLS_CA_FILE")
+	certFile := os.Getenv(prefix + "_TLS_CERT_FILE")
+	keyFile := os.Getenv(prefix + "_TLS_KEY_FILE")
+
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return nil, minVersion, nil
+	}
+
+	files, err := checkers.NewTLSFiles(caFile, certFile, keyFile)
+	if err != nil {
+		return nil, 0, fmt.Errorf("loading %s TLS files: %w", prefix, err)
+	}
+
+	return files, minVersion, nil
+}
+
+// envRetryPolicy overrides the checker's default retry policy with the
+// settings given in the environment.
+func envRetryPolicy(prefix string, def checkers.RetryPolicy) (checkers.RetryPolicy, error) {
+	p := def
+	var err error
+
+	if p.Timeout, err = envDuration(prefix+"_TIMEOUT", p.Timeout); err != nil {
+		return p, err
+	}
+	if p.Attempts, err = envInt(prefix+"_RETRY_ATTEMPTS", p.Attempts); err != nil {
+		return p, err
+	}
+	if p.Delay, err = envDuration(prefix+"_RETRY_DELAY", p.Delay); err != nil {
+		return p, err
+	}
+	if p.MaxDelay, err = envDuration(prefix+"_RETRY_MAX_DELAY", p.MaxDelay); err != nil {
+		return p, err
+	}
+	if p.Jitter, err = envFloat(prefix+"_RETRY_JITTER", p.Jitter); err != nil {
+		return p, err
+	}
+
+	if backoff := os.Getenv(prefix + "_RETRY_BACKOFF"); backoff != "" {
+		p.Backoff = checkers.Backoff(backoff)
+	}
+
+	if err := p.Validate(); err != nil {
+		return p, fmt.Errorf("invalid %s retry policy: %w", prefix, err)
+	}
+
+	// A check running out of time counts as unhealthy, the worst case must
+	// fit into the evaluation
+	budget := gslb.CheckTimeout(evalInterval)
+	if p.Timeout == 0 {
+		return p, fmt.Errorf("invalid %s retry policy: timeout must be set", prefix)
+	}
+	if d := p.MaxDuration(); d > budget {
+		return p, fmt.Errorf("invalid %s retry policy: attempts, timeouts and delays take up to %s, more than the check budget of %s", prefix, d, budget)
+	}
+
+	return p, nil
+}
+
+// envLatencyThreshold returns the latency limits given in the environment, or
+// nil if none is set.
+func envLatencyThreshold(prefix string) (*checkers.LatencyThreshold, error) {
+	l := &checkers.LatencyThreshold{}
+	var err error
+
+	if l.Max, err = envDuration(prefix+"_LATENCY_MAX", 0); err != nil {
+		return nil, err
+	}
+	if l.PercentileMax, err = envDuration(prefix+"_LATENCY_PERCENTILE_MAX", 0); err != nil {
+		return nil, err
+	}
+	if l.Max == 0 && l.PercentileMax == 0 {
+		return nil, nil
+	}
+
+	if l.Percentile, err = envFloat(prefix+"_LATENCY_PERCENTILE", 95); err != nil {
+		return nil, err
+	}
+	if l.Window, err = envInt(prefix+"_LATENCY_WINDOW", 20); err != nil {
+		return nil, err
+	}
+
+	switch action := os.Getenv(prefix + "_LATENCY_ACTION"); action {
+	case "", "unhealthy":
+	case "degraded":
+		l.Degrade = true
+	default:
+		return nil, fmt.Errorf("unsupported %s_LATENCY_ACTION %q", prefix, action)
+	}
+
+	if err := l.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s latency threshold: %w", prefix, err)
+	}
+
+	return l, nil
+}
+
+// withDefaultPort adds port to address if it has none.
+func withDefaultPort(address, port string) string {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		// Brackets of an IPv6 address such as [::1] are added again
+		host := strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+		return net.JoinHostPort(host, port)
+	}
+
+	return address
+}
+
+// envInt parses an int from the given environment variable, returning def if
+// it is not set.
+func envInt(name string, def int) (int, error) {
+	val := os.Getenv(name)
+	if val == "" {
+		return def, nil
+	}
+
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", name, err)
+	}
+
+	return i, nil
+}
+
+// envFloat parses a float64 from the given environment variable, returning
+// def if it is not set.
+func envFloat(name string, def float64) (float64, error) {
+	val := os.Getenv(name)
+	if val == "" {
+		return def, nil
+	}
+
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", name, err)
+	}
+
+	return f, nil
+}
+
+// envDuration parses a time.Duration from the given environment variable,
+// returning def if it is not set.
+func envDuration(name string, def time.Duration) (time.Duration, error) {
+	val := os.Getenv(name)
+	if val == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", name, err)
+	}
+
+	return d, nil
+}
+
+// envDays parses a number of days from the given environment variable,
+// returning zero if it is not set.
+func envDays(name string) (time.Duration, error) {
+	days, err := envInt(name, 0)
+	if err != nil {
+		return 0, err
+	}
+	if days < 0 {
+		return 0, fmt.Errorf("%s must not be negative", name)
+	}
+
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// envDefault returns the value of the environment variable, or def if it is
+// not set at all. Setting it to an empty value disables the default.
+func envDefault(name, def string) string {
+	if val, ok := os.LookupEnv(name); ok {
+		return val
+	}
+
+	return def
+}
+
+// envHeaders collects HTTP headers from all environment variables starting
+// with prefix, e.g. prefix X_API_KEY=secret becomes X-Api-Key: secret.
+func envHeaders(prefix string) http.Header {
+	var headers http.Header
+
+	for _, kv := range os.Environ() {
+		name, val, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, prefix) || name == prefix {
+			continue
+		}
+
+		if headers == nil {
+			headers = http.Header{}
+		}
+
+		header := strings.ReplaceAll(strings.TrimPrefix(name, prefix), "_", "-")
+		headers.Add(header, val)
+	}
+
+	return headers
+}
+
+// envVars collects the environment variables starting with prefix as
+// KEY=value pairs with the prefix removed.
+func envVars(prefix string) []string {
+	var vars []string
+
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, prefix) && !strings.HasPrefix(kv, prefix+"=") {
+			vars = append(vars, strings.TrimPrefix(kv, prefix))
+		}
+	}
+
+	return vars
+}
+
+// envList splits a comma separated environment variable into its trimmed,
+// non-empty elements.
+func envList(name string) []string {
+	var list []string
+	for _, s := range strings.Split(os.Getenv(name), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+
+	return list
+}
+
+// envLabels parses comma separated name=value label pairs from the given
+// environment variable.
+func envLabels(name string) (map[string]string, error) {
+	var labels map[string]string
+
+	for _, pair := range envList(name) {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("parsing %s: invalid label %q, expected name=value", name, pair)
+		}
+
+		if labels == nil {
+			labels = map[string]string{}
+		}
+
+		labels[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	return labels, nil
+}
+
+// splitArgs splits s into arguments like a shell, without expansions.
+// Single quotes keep everything literally, within double quotes and outside
+// of quotes a backslash escapes the next character.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", s)
+	}
+
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args, nil
+}
+
+// envUnquote reads the given environment variable and interprets Go escape
+// sequences such as \r\n, which cannot easily be written in most env files.
+func envUnquote(name string) (string, error) {
+	val := os.Getenv(name)
+	if val == "" {
+		return "", nil
+	}
+
+	s, err := strconv.Unquote(`"` + val + `"`)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s: %w", name, err)
+	}
+
+	return s, nil
+}
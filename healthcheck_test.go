@@ -0,0 +1,108 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/microfast-ch/gslb-switcher/internal/checkers"
+)
+
+func TestWithDefaultPort(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"10.0.0.1", "10.0.0.1:53"},
+		{"10.0.0.1:5353", "10.0.0.1:5353"},
+		{"dns.example.com", "dns.example.com:53"},
+		{"::1", "[::1]:53"},
+		{"[::1]", "[::1]:53"},
+		{"[::1]:5353", "[::1]:5353"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if got := withDefaultPort(tt.address, "53"); got != tt.want {
+				t.Errorf("withDefaultPort() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvRetryPolicy_Budget(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts string
+		timeout  string
+		wantErr  bool
+	}{
+		{"default", "", "", false},
+		{"within budget", "4", "2s", false},
+		{"exceeds budget", "5", "10s", true},
+		{"no timeout", "", "0s", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_CHECK_RETRY_ATTEMPTS", tt.attempts)
+			t.Setenv("TEST_CHECK_TIMEOUT", tt.timeout)
+
+			if _, err := envRetryPolicy("TEST_CHECK", checkers.DefaultRetryPolicy()); (err != nil) != tt.wantErr {
+				t.Errorf("envRetryPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{input: "", want: nil},
+		{input: "-H 10.0.0.101  -w 2", want: []string{"-H", "10.0.0.101", "-w", "2"}},
+		{input: `-s "Service Name" -d ''`, want: []string{"-s", "Service Name", "-d", ""}},
+		{input: `-r 'a "b" c' -e \$HOME`, want: []string{"-r", `a "b" c`, "-e", "$HOME"}},
+		{input: `-s "say \"hi\""`, want: []string{"-s", `say "hi"`}},
+		{input: `-s "Service`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := splitArgs(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCompositeChecker_MemberNames(t *testing.T) {
+	// Members named like an option of the composite check itself
+	t.Setenv("TEST_CHECK", "type,timeout")
+	t.Setenv("TEST_CHECK_TYPE", "composite")
+	t.Setenv("TEST_CHECK_MEMBER_TYPE", "10.0.0.1:80")
+	t.Setenv("TEST_CHECK_MEMBER_TYPE_TYPE", "tcp")
+	t.Setenv("TEST_CHECK_MEMBER_TIMEOUT", "10.0.0.1:443")
+	t.Setenv("TEST_CHECK_MEMBER_TIMEOUT_TYPE", "tcp")
+	t.Setenv("TEST_CHECK_MEMBER_TIMEOUT_WEIGHT", "2")
+
+	chk, err := newHealthChecker("TEST_CHECK", "api.example.com", nil)
+	if err != nil {
+		t.Fatalf("newHealthChecker() unexpected error: %v", err)
+	}
+
+	composite, ok := chk.(*checkers.CompositeChecker)
+	if !ok {
+		t.Fatalf("newHealthChecker() = %T, want *checkers.CompositeChecker", chk)
+	}
+
+	if len(composite.Members) != 2 || composite.Members[0].Name != "type" || composite.Members[1].Weight != 2 {
+		t.Errorf("newHealthChecker() members = %+v, want type and timeout with weight 2", composite.Members)
+	}
+}
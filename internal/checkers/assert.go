@@ -0,0 +1,192 @@
+package checkers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BodyAssertion checks the body of a health check response. Check returns a
+// description of the failure, or an empty string if the assertion passed.
+type BodyAssertion interface {
+	Check(body []byte) string
+}
+
+// ContainsAssertion requires the body to contain the given substring.
+type ContainsAssertion string
+
+func (a ContainsAssertion) Check(body []byte) string {
+	if bytes.Contains(body, []byte(a)) {
+		return ""
+	}
+
+	return fmt.Sprintf("body does not contain %q", string(a))
+}
+
+// RegexAssertion requires the body to match the regular expression.
+type RegexAssertion struct {
+	*regexp.Regexp
+}
+
+func (a RegexAssertion) Check(body []byte) string {
+	if a.Match(body) {
+		return ""
+	}
+
+	return fmt.Sprintf("body does not match /%s/", a.String())
+}
+
+// JSONPathAssertion compares the JSON value at Path with Value. Path is a dot
+// separated list of object keys and array indexes, e.g. "checks.db.status" or
+// "$.items[0].state".
+type JSONPathAssertion struct {
+	Path  string
+	Op    string
+	Value string
+}
+
+var jsonAssertionRe = regexp.MustCompile(`^\s*(\S+?)\s*(==|!=|<=|>=|<|>)\s*(.*?)\s*$`)
+
+// ParseJSONPathAssertion parses an expression like `status == "ok"` or
+// `checks.db.latency_ms < 100`.
+func ParseJSONPathAssertion(expr string) (*JSONPathAssertion, error) {
+	m := jsonAssertionRe.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, fmt.Errorf("invalid JSON assertion %q, expected <path> <op> <value>", expr)
+	}
+
+	a := &JSONPathAssertion{Path: m[1], Op: m[2], Value: m[3]}
+
+	// Ordering comparisons only make sense for numbers
+	if a.Op != "==" && a.Op != "!=" {
+		if _, err := strconv.ParseFloat(a.Value, 64); err != nil {
+			return nil, fmt.Errorf("invalid JSON assertion %q, %s requires a number", expr, a.Op)
+		}
+	}
+
+	return a, nil
+}
+
+func (a *JSONPathAssertion) String() string {
+	return fmt.Sprintf("%s %s %s", a.Path, a.Op, a.Value)
+}
+
+func (a *JSONPathAssertion) Check(body []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Sprintf("body is not valid JSON: %s", err)
+	}
+
+	val, ok := jsonLookup(doc, a.Path)
+	if !ok {
+		return fmt.Sprintf("%s: path not found", a)
+	}
+
+	if !a.compare(val) {
+		got, _ := json.Marshal(val)
+		return fmt.Sprintf("%s: got %s", a, got)
+	}
+
+	return ""
+}
+
+func (a *JSONPathAssertion) compare(val any) bool {
+	// Compare numerically if both sides are numbers
+	if num, ok := val.(json.Number); ok {
+		got, err1 := num.Float64()
+		want, err2 := strconv.ParseFloat(a.Value, 64)
+		if err1 == nil && err2 == nil {
+			return compareNumbers(got, a.Op, want)
+		}
+	}
+
+	var equal bool
+	switch v := val.(type) {
+	case string:
+		equal = v == unquote(a.Value)
+	case bool:
+		equal = strconv.FormatBool(v) == a.Value
+	case nil:
+		equal = a.Value == "null"
+	case json.Number:
+		equal = v.String() == a.Value
+	default:
+		// Objects and arrays can't be compared with a scalar
+		equal = false
+	}
+
+	switch a.Op {
+	case "==":
+		return equal
+	case "!=":
+		return !equal
+	default:
+		return false
+	}
+}
+
+// compareNumbers applies the comparison operator op to got and want.
+func compareNumbers(got float64, op string, want float64) bool {
+	switch op {
+	case "==":
+		return got == want
+	case "!=":
+		return got != want
+	case "<":
+		return got < want
+	case "<=":
+		return got <= want
+	case ">":
+		return got > want
+	case ">=":
+		return got >= want
+	default:
+		return false
+	}
+}
+
+// jsonLookup walks the decoded JSON document along path.
+func jsonLookup(doc any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+
+	cur := doc
+	if path == "" {
+		return cur, true
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			cur = node[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return cur, true
+}
+
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+
+	return s
+}
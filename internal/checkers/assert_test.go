@@ -0,0 +1,113 @@
+package checkers
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONPathAssertion(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    JSONPathAssertion
+		wantErr bool
+	}{
+		{
+			expr: `status == "ok"`,
+			want: JSONPathAssertion{Path: "status", Op: "==", Value: `"ok"`},
+		},
+		{
+			expr: "checks.db.latency_ms<100",
+			want: JSONPathAssertion{Path: "checks.db.latency_ms", Op: "<", Value: "100"},
+		},
+		{
+			expr: "$.items[0].ready != false",
+			want: JSONPathAssertion{Path: "$.items[0].ready", Op: "!=", Value: "false"},
+		},
+		{
+			expr:    "status ok",
+			wantErr: true,
+		},
+		{
+			expr:    "status >= ok",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseJSONPathAssertion(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseJSONPathAssertion() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseJSONPathAssertion() unexpected error: %v", err)
+			}
+
+			if *got != tt.want {
+				t.Errorf("ParseJSONPathAssertion() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyAssertions(t *testing.T) {
+	body := []byte(`{"status":"ok","version":"1.2.3","checks":{"db":{"up":true,"latency_ms":42}},"items":[{"name":"a"},{"name":"b"}],"extra":null}`)
+
+	mustJSON := func(expr string) BodyAssertion {
+		a, err := ParseJSONPathAssertion(expr)
+		if err != nil {
+			t.Fatalf("ParseJSONPathAssertion(%q) failed: %v", expr, err)
+		}
+		return a
+	}
+
+	tests := []struct {
+		name      string
+		assertion BodyAssertion
+		wantPass  bool
+	}{
+		{"contains match", ContainsAssertion(`"status":"ok"`), true},
+		{"contains mismatch", ContainsAssertion("degraded"), false},
+		{"regex match", RegexAssertion{regexp.MustCompile(`"version":"1\.\d+`)}, true},
+		{"regex mismatch", RegexAssertion{regexp.MustCompile(`"version":"2\.`)}, false},
+		{"json string quoted", mustJSON(`status == "ok"`), true},
+		{"json string bare", mustJSON(`status == ok`), true},
+		{"json string mismatch", mustJSON(`status == degraded`), false},
+		{"json string not equal", mustJSON(`status != degraded`), true},
+		{"json bool", mustJSON(`checks.db.up == true`), true},
+		{"json number less", mustJSON(`checks.db.latency_ms < 100`), true},
+		{"json number greater", mustJSON(`checks.db.latency_ms > 100`), false},
+		{"json number equal", mustJSON(`checks.db.latency_ms == 42.0`), true},
+		{"json array index", mustJSON(`items[1].name == b`), true},
+		{"json array dot index", mustJSON(`$.items.0.name == a`), true},
+		{"json null", mustJSON(`extra == null`), true},
+		{"json missing path", mustJSON(`checks.cache.up == true`), false},
+		{"json object compared", mustJSON(`checks == ok`), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := tt.assertion.Check(body)
+			if (msg == "") != tt.wantPass {
+				t.Errorf("Check() = %q, want pass = %v", msg, tt.wantPass)
+			}
+		})
+	}
+}
+
+func TestJSONPathAssertion_InvalidJSON(t *testing.T) {
+	a, err := ParseJSONPathAssertion("status == ok")
+	if err != nil {
+		t.Fatalf("ParseJSONPathAssertion() failed: %v", err)
+	}
+
+	msg := a.Check([]byte("OK"))
+	if !strings.Contains(msg, "not valid JSON") {
+		t.Errorf("Check() = %q, want invalid JSON failure", msg)
+	}
+}
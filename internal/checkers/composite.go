@@ -0,0 +1,184 @@
+package checkers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+type CompositeMode string
+
+const (
+	// CompositeAll requires all members to pass.
+	CompositeAll CompositeMode = "all"
+	// CompositeAny requires at least one member to pass.
+	CompositeAny CompositeMode = "any"
+	// CompositeQuorum requires the weights of the passing members to add
+	// up to at least Quorum.
+	CompositeQuorum CompositeMode = "quorum"
+)
+
+// CompositeMember is a named child checker of a CompositeChecker.
+type CompositeMember struct {
+	Name    string
+	Checker gslb.HealthChecker
+
+	// Weight counts towards the quorum, zero counts as one.
+	Weight int
+}
+
+func (m CompositeMember) weight() int {
+	if m.Weight == 0 {
+		return 1
+	}
+
+	return m.Weight
+}
+
+// CompositeChecker runs its members concurrently and combines their results.
+// The target is healthy if the healthy members satisfy the mode, degraded if
+// only the healthy and degraded members together do and unhealthy otherwise.
+type CompositeChecker struct {
+	Mode    CompositeMode
+	Quorum  int
+	Members []CompositeMember
+}
+
+func NewCompositeChecker(mode CompositeMode, members ...CompositeMember) *CompositeChecker {
+	return &CompositeChecker{
+		Mode:    mode,
+		Members: members,
+	}
+}
+
+// Validate reports configuration errors of the checker.
+func (c *CompositeChecker) Validate() error {
+	if len(c.Members) == 0 {
+		return fmt.Errorf("composite check has no members")
+	}
+
+	total := 0
+	for _, m := range c.Members {
+		if m.Weight < 0 {
+			return fmt.Errorf("weight of %s must not be negative", m.Name)
+		}
+
+		total += m.weight()
+	}
+
+	switch c.Mode {
+	case CompositeAll, CompositeAny:
+	case CompositeQuorum:
+		if c.Quorum <= 0 || c.Quorum > total {
+			return fmt.Errorf("quorum must be between 1 and the total weight %d", total)
+		}
+	default:
+		return fmt.Errorf("unsupported composite mode %q", c.Mode)
+	}
+
+	return nil
+}
+
+// Check implements gslb.HealthChecker.
+func (c *CompositeChecker) Check(ctx context.Context) (gslb.Result, error) {
+	if err := c.Validate(); err != nil {
+		return gslb.Result{}, err
+	}
+
+	results := make([]gslb.Result, len(c.Members))
+	errs := make([]error, len(c.Members))
+
+	var wg sync.WaitGroup
+	for i, m := range c.Members {
+		wg.Go(func() {
+			results[i], errs[i] = m.Checker.Check(ctx)
+		})
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return gslb.Result{}, fmt.Errorf("checking %s: %w", c.Members[i].Name, err)
+		}
+	}
+
+	return c.combine(results), nil
+}
+
+// combine aggregates the member results, which are in the order of Members.
+func (c *CompositeChecker) combine(results []gslb.Result) gslb.Result {
+	var (
+		healthy, up, total int
+		res                gslb.Result
+		members            []string
+	)
+
+	res.Details = map[string]string{}
+
+	for i, r := range results {
+		m := c.Members[i]
+		total += m.weight()
+
+		switch {
+		case r.State == gslb.StateHealthy:
+			healthy += m.weight()
+			up += m.weight()
+		case r.Healthy():
+			up += m.weight()
+		case res.Class == gslb.ErrorNone:
+			// Report why the first failing member failed
+			res.Class = r.Class
+		}
+
+		// Members run concurrently, so the slowest one sets the latency
+		res.Latency = max(res.Latency, r.Latency)
+		res.Attempts = max(res.Attempts, r.Attempts)
+
+		members = append(members, fmt.Sprintf("%s: %s (%s)", m.Name, r.State, r.Status))
+		res.Details[m.Name] = r.State.String()
+		for k, v := range r.Details {
+			res.Details[m.Name+"."+k] = v
+		}
+	}
+
+	switch {
+	case c.satisfied(healthy, total):
+		res.State = gslb.StateHealthy
+		res.Class = gslb.ErrorNone
+	case c.satisfied(up, total):
+		res.State = gslb.StateDegraded
+		res.Class = gslb.ErrorNone
+	default:
+		res.State = gslb.StateUnhealthy
+	}
+
+	res.Status = fmt.Sprintf("%d/%d passed, %s: %s", up, total, c.requirement(total), strings.Join(members, "; "))
+
+	return res
+}
+
+// satisfied reports whether the weight of passing members meets the mode.
+func (c *CompositeChecker) satisfied(weight, total int) bool {
+	switch c.Mode {
+	case CompositeAny:
+		return weight > 0
+	case CompositeQuorum:
+		return weight >= c.Quorum
+	default:
+		return weight == total
+	}
+}
+
+func (c *CompositeChecker) requirement(total int) string {
+	switch c.Mode {
+	case CompositeAny:
+		return "any required"
+	case CompositeQuorum:
+		return fmt.Sprintf("%d required", c.Quorum)
+	default:
+		return fmt.Sprintf("%d required", total)
+	}
+}
@@ -0,0 +1,201 @@
+package checkers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// staticChecker returns a fixed result after an optional delay.
+type staticChecker struct {
+	result gslb.Result
+	err    error
+	delay  time.Duration
+}
+
+func (s staticChecker) Check(ctx context.Context) (gslb.Result, error) {
+	if err := sleepContext(ctx, s.delay); err != nil {
+		return gslb.Result{}, err
+	}
+
+	return s.result, s.err
+}
+
+func member(name string, state gslb.State, weight int) CompositeMember {
+	return CompositeMember{
+		Name:    name,
+		Checker: staticChecker{result: gslb.Result{State: state, Status: state.String(), Class: classOf(state)}},
+		Weight:  weight,
+	}
+}
+
+func classOf(state gslb.State) gslb.ErrorClass {
+	if state == gslb.StateUnhealthy {
+		return gslb.ErrorConnection
+	}
+
+	return gslb.ErrorNone
+}
+
+func TestCompositeChecker_Check(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      CompositeMode
+		quorum    int
+		members   []CompositeMember
+		wantState gslb.State
+	}{
+		{
+			name:      "all healthy",
+			mode:      CompositeAll,
+			members:   []CompositeMember{member("api", gslb.StateHealthy, 0), member("db", gslb.StateHealthy, 0)},
+			wantState: gslb.StateHealthy,
+		},
+		{
+			name:      "all with one unhealthy",
+			mode:      CompositeAll,
+			members:   []CompositeMember{member("api", gslb.StateHealthy, 0), member("db", gslb.StateUnhealthy, 0)},
+			wantState: gslb.StateUnhealthy,
+		},
+		{
+			name:      "all with one degraded",
+			mode:      CompositeAll,
+			members:   []CompositeMember{member("api", gslb.StateHealthy, 0), member("db", gslb.StateDegraded, 0)},
+			wantState: gslb.StateDegraded,
+		},
+		{
+			name:      "any with one healthy",
+			mode:      CompositeAny,
+			members:   []CompositeMember{member("api", gslb.StateUnhealthy, 0), member("db", gslb.StateHealthy, 0)},
+			wantState: gslb.StateHealthy,
+		},
+		{
+			name:      "any with none up",
+			mode:      CompositeAny,
+			members:   []CompositeMember{member("api", gslb.StateUnhealthy, 0), member("db", gslb.StateUnknown, 0)},
+			wantState: gslb.StateUnhealthy,
+		},
+		{
+			name:   "quorum met",
+			mode:   CompositeQuorum,
+			quorum: 2,
+			members: []CompositeMember{
+				member("a", gslb.StateHealthy, 0), member("b", gslb.StateUnhealthy, 0), member("c", gslb.StateHealthy, 0),
+			},
+			wantState: gslb.StateHealthy,
+		},
+		{
+			name:   "quorum missed",
+			mode:   CompositeQuorum,
+			quorum: 2,
+			members: []CompositeMember{
+				member("a", gslb.StateHealthy, 0), member("b", gslb.StateUnhealthy, 0), member("c", gslb.StateUnhealthy, 0),
+			},
+			wantState: gslb.StateUnhealthy,
+		},
+		{
+			name:   "quorum met by weight",
+			mode:   CompositeQuorum,
+			quorum: 3,
+			members: []CompositeMember{
+				member("api", gslb.StateHealthy, 3), member("b", gslb.StateUnhealthy, 0), member("c", gslb.StateUnhealthy, 0),
+			},
+			wantState: gslb.StateHealthy,
+		},
+		{
+			name:   "quorum met only with degraded",
+			mode:   CompositeQuorum,
+			quorum: 2,
+			members: []CompositeMember{
+				member("a", gslb.StateHealthy, 0), member("b", gslb.StateDegraded, 0), member("c", gslb.StateUnhealthy, 0),
+			},
+			wantState: gslb.StateDegraded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewCompositeChecker(tt.mode, tt.members...)
+			checker.Quorum = tt.quorum
+
+			res, err := checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState {
+				t.Errorf("Check() state = %v, want %v (status %q)", res.State, tt.wantState, res.Status)
+			}
+
+			if (res.State == gslb.StateUnhealthy) != (res.Class != gslb.ErrorNone) {
+				t.Errorf("Check() class = %q for state %v", res.Class, res.State)
+			}
+
+			for _, m := range tt.members {
+				if !strings.Contains(res.Status, m.Name+": ") {
+					t.Errorf("Check() status = %q, want result of %s", res.Status, m.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestCompositeChecker_Check_Concurrent(t *testing.T) {
+	slow := staticChecker{result: gslb.Result{State: gslb.StateHealthy}, delay: 100 * time.Millisecond}
+	checker := NewCompositeChecker(CompositeAll,
+		CompositeMember{Name: "a", Checker: slow},
+		CompositeMember{Name: "b", Checker: slow},
+		CompositeMember{Name: "c", Checker: slow},
+	)
+
+	start := time.Now()
+
+	if _, err := checker.Check(context.Background()); err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Check() took %v, members did not run concurrently", elapsed)
+	}
+}
+
+func TestCompositeChecker_Check_MemberError(t *testing.T) {
+	checker := NewCompositeChecker(CompositeAny,
+		member("api", gslb.StateHealthy, 0),
+		CompositeMember{Name: "db", Checker: staticChecker{err: errors.New("invalid address")}},
+	)
+
+	_, err := checker.Check(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "db") {
+		t.Errorf("Check() error = %v, want error of member db", err)
+	}
+}
+
+func TestCompositeChecker_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker *CompositeChecker
+		wantErr bool
+	}{
+		{"valid", NewCompositeChecker(CompositeAll, member("a", gslb.StateHealthy, 0)), false},
+		{"no members", NewCompositeChecker(CompositeAll), true},
+		{"unsupported mode", NewCompositeChecker("most", member("a", gslb.StateHealthy, 0)), true},
+		{"negative weight", NewCompositeChecker(CompositeAll, member("a", gslb.StateHealthy, -1)), true},
+		{"quorum unset", NewCompositeChecker(CompositeQuorum, member("a", gslb.StateHealthy, 0)), true},
+		{"quorum above total weight", &CompositeChecker{
+			Mode: CompositeQuorum, Quorum: 3, Members: []CompositeMember{member("a", gslb.StateHealthy, 2)},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.checker.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
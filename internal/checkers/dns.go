@@ -0,0 +1,270 @@
+package checkers
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+var dnsTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"NS":    dnsmessage.TypeNS,
+	"PTR":   dnsmessage.TypePTR,
+	"SOA":   dnsmessage.TypeSOA,
+	"SRV":   dnsmessage.TypeSRV,
+	"TXT":   dnsmessage.TypeTXT,
+}
+
+var dnsRCodes = map[dnsmessage.RCode]string{
+	dnsmessage.RCodeSuccess:        "NOERROR",
+	dnsmessage.RCodeFormatError:    "FORMERR",
+	dnsmessage.RCodeServerFailure:  "SERVFAIL",
+	dnsmessage.RCodeNameError:      "NXDOMAIN",
+	dnsmessage.RCodeNotImplemented: "NOTIMP",
+	dnsmessage.RCodeRefused:        "REFUSED",
+}
+
+type DNSChecker struct {
+	Server string
+	Name   string
+	Type   string
+	TCP    bool
+
+	// RCodes lists the response codes treated as healthy, NOERROR if empty.
+	RCodes []string
+
+	// Expect, if set, must match the value of one of the answer records,
+	// e.g. an IP address for A records or a host name for CNAME records.
+	Expect string
+
+	Retry RetryPolicy
+}
+
+func NewDNSChecker(server, name, qtype string) *DNSChecker {
+	return &DNSChecker{
+		Server: server,
+		Name:   name,
+		Type:   qtype,
+		Retry:  DefaultRetryPolicy(),
+	}
+}
+
+// Check implements gslb.HealthChecker.
+func (c *DNSChecker) Check(ctx context.Context) (gslb.Result, error) {
+	// Validate configuration before sending any query
+	if _, _, err := net.SplitHostPort(c.Server); err != nil {
+		return gslb.Result{}, err
+	}
+
+	qtype, ok := dnsTypes[strings.ToUpper(c.Type)]
+	if !ok {
+		return gslb.Result{}, fmt.Errorf("unsupported DNS query type %q", c.Type)
+	}
+
+	qname, err := dnsmessage.NewName(dnsFQDN(c.Name))
+	if err != nil {
+		return gslb.Result{}, fmt.Errorf("invalid DNS query name %q: %w", c.Name, err)
+	}
+
+	for _, rc := range c.RCodes {
+		if !dnsKnownRCode(rc) {
+			return gslb.Result{}, fmt.Errorf("unsupported DNS rcode %q", rc)
+		}
+	}
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		return c.query(ctx, qname, qtype)
+	})
+}
+
+// query sends a single DNS query and evaluates the response. Failures are
+// reported through the result, as they mean the server is unhealthy.
+func (c *DNSChecker) query(ctx context.Context, qname dnsmessage.Name, qtype dnsmessage.Type) gslb.Result {
+	start := time.Now()
+	id := uint16(rand.Uint32())
+
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: qname, Type: qtype, Class: dnsmessage.ClassINET},
+		},
+	}
+
+	packet, err := msg.Pack()
+	if err != nil {
+		return failure(gslb.ErrorUnknown, fmt.Sprintf("packing query: %s", err), time.Since(start))
+	}
+
+	network := "udp"
+	if c.TCP {
+		network = "tcp"
+	}
+
+	conn, err := dialContext(ctx, network, c.Server)
+	if err != nil {
+		return failure(errorClass(err), err.Error(), time.Since(start))
+	}
+	defer conn.Close() //nolint:errcheck
+
+	var resp []byte
+	if c.TCP {
+		resp, err = dnsExchangeTCP(conn, packet)
+	} else {
+		resp, err = dnsExchangeUDP(conn, packet)
+	}
+	if err != nil {
+		return failure(errorClass(err), err.Error(), time.Since(start))
+	}
+
+	var answer dnsmessage.Message
+	if err := answer.Unpack(resp); err != nil {
+		return failure(gslb.ErrorResponse, fmt.Sprintf("unpacking response: %s", err), time.Since(start))
+	}
+
+	if answer.Header.ID != id || !answer.Header.Response {
+		return failure(gslb.ErrorResponse, "response does not match query", time.Since(start))
+	}
+
+	rcode := dnsRCodeString(answer.Header.RCode)
+	status := fmt.Sprintf("%s, %d answers", rcode, len(answer.Answers))
+	details := map[string]string{"rcode": rcode}
+
+	var res gslb.Result
+	switch {
+	case !c.rcodeAccepted(rcode):
+		res = failure(gslb.ErrorStatus, status, time.Since(start))
+	case c.Expect != "" && !slices.ContainsFunc(answer.Answers, func(rr dnsmessage.Resource) bool {
+		return dnsValueMatches(rr.Body, c.Expect)
+	}):
+		res = failure(gslb.ErrorResponse, fmt.Sprintf("%s, expected answer %q not found", status, c.Expect), time.Since(start))
+	default:
+		res = gslb.Result{State: gslb.StateHealthy, Status: status, Latency: time.Since(start)}
+	}
+
+	res.Details = details
+
+	return res
+}
+
+func (c *DNSChecker) rcodeAccepted(rcode string) bool {
+	if len(c.RCodes) == 0 {
+		return rcode == "NOERROR"
+	}
+
+	for _, rc := range c.RCodes {
+		if strings.EqualFold(rc, rcode) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func dnsExchangeUDP(conn net.Conn, packet []byte) ([]byte, error) {
+	if _, err := conn.Write(packet); err != nil {
+		return nil, fmt.Errorf("sending query: %w", err)
+	}
+
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	return buf[:n], nil
+}
+
+func dnsExchangeTCP(conn net.Conn, packet []byte) ([]byte, error) {
+	// Messages over TCP are prefixed with a two byte length field
+	framed := binary.BigEndian.AppendUint16(nil, uint16(len(packet)))
+	framed = append(framed, packet...)
+
+	if _, err := conn.Write(framed); err != nil {
+		return nil, fmt.Errorf("sending query: %w", err)
+	}
+
+	var length uint16
+	if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+		return nil, fmt.Errorf("reading response length: %w", err)
+	}
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	return buf, nil
+}
+
+func dnsValueMatches(body dnsmessage.ResourceBody, expect string) bool {
+	switch b := body.(type) {
+	case *dnsmessage.AResource:
+		return dnsIPMatches(b.A[:], expect)
+	case *dnsmessage.AAAAResource:
+		return dnsIPMatches(b.AAAA[:], expect)
+	case *dnsmessage.CNAMEResource:
+		return dnsNameMatches(b.CNAME, expect)
+	case *dnsmessage.NSResource:
+		return dnsNameMatches(b.NS, expect)
+	case *dnsmessage.PTRResource:
+		return dnsNameMatches(b.PTR, expect)
+	case *dnsmessage.MXResource:
+		return dnsNameMatches(b.MX, expect)
+	case *dnsmessage.SRVResource:
+		return dnsNameMatches(b.Target, expect)
+	case *dnsmessage.SOAResource:
+		return dnsNameMatches(b.NS, expect)
+	case *dnsmessage.TXTResource:
+		return strings.Join(b.TXT, "") == expect
+	default:
+		return false
+	}
+}
+
+func dnsIPMatches(ip net.IP, expect string) bool {
+	want := net.ParseIP(expect)
+	return want != nil && want.Equal(ip)
+}
+
+func dnsNameMatches(name dnsmessage.Name, expect string) bool {
+	return strings.EqualFold(name.String(), dnsFQDN(expect))
+}
+
+func dnsFQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+
+	return name + "."
+}
+
+func dnsRCodeString(rcode dnsmessage.RCode) string {
+	if s, ok := dnsRCodes[rcode]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("RCODE%d", rcode)
+}
+
+func dnsKnownRCode(rcode string) bool {
+	for _, s := range dnsRCodes {
+		if strings.EqualFold(s, rcode) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,287 @@
+package checkers
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// fakeDNSHandler builds the answer for a query, returning the rcode and the
+// A records to include.
+type fakeDNSHandler func(q dnsmessage.Question) (dnsmessage.RCode, []string)
+
+func fakeDNSResponse(t *testing.T, query []byte, handler fakeDNSHandler) []byte {
+	t.Helper()
+
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil {
+		t.Errorf("Failed to unpack query: %v", err)
+		return nil
+	}
+
+	q := msg.Questions[0]
+	rcode, ips := handler(q)
+
+	resp := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: msg.Header.ID, Response: true, RCode: rcode},
+		Questions: msg.Questions,
+	}
+
+	for _, ip := range ips {
+		var a [4]byte
+		copy(a[:], net.ParseIP(ip).To4())
+
+		resp.Answers = append(resp.Answers, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+			Body:   &dnsmessage.AResource{A: a},
+		})
+	}
+
+	packet, err := resp.Pack()
+	if err != nil {
+		t.Errorf("Failed to pack response: %v", err)
+		return nil
+	}
+
+	return packet
+}
+
+func startFakeDNSServerUDP(t *testing.T, handler fakeDNSHandler) string {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { pc.Close() }) //nolint:errcheck
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			if resp := fakeDNSResponse(t, buf[:n], handler); resp != nil {
+				pc.WriteTo(resp, addr) //nolint:errcheck
+			}
+		}
+	}()
+
+	return pc.LocalAddr().String()
+}
+
+func startFakeDNSServerTCP(t *testing.T, handler fakeDNSHandler) string {
+	t.Helper()
+
+	return startTCPServer(t, func(c net.Conn) {
+		var length uint16
+		if err := binary.Read(c, binary.BigEndian, &length); err != nil {
+			return
+		}
+
+		query := make([]byte, length)
+		if _, err := io.ReadFull(c, query); err != nil {
+			return
+		}
+
+		resp := fakeDNSResponse(t, query, handler)
+		framed := binary.BigEndian.AppendUint16(nil, uint16(len(resp)))
+		c.Write(append(framed, resp...)) //nolint:errcheck
+	})
+}
+
+func TestDNSChecker_Check(t *testing.T) {
+	answer := func(q dnsmessage.Question) (dnsmessage.RCode, []string) {
+		switch q.Name.String() {
+		case "api.example.com.":
+			return dnsmessage.RCodeSuccess, []string{"10.0.0.1", "10.0.0.2"}
+		case "broken.example.com.":
+			return dnsmessage.RCodeServerFailure, nil
+		default:
+			return dnsmessage.RCodeNameError, nil
+		}
+	}
+
+	tests := []struct {
+		name        string
+		qname       string
+		tcp         bool
+		rcodes      []string
+		expect      string
+		wantHealthy bool
+		wantStatus  string
+	}{
+		{
+			name:        "NOERROR over UDP",
+			qname:       "api.example.com",
+			wantHealthy: true,
+			wantStatus:  "NOERROR, 2 answers",
+		},
+		{
+			name:        "NOERROR over TCP",
+			qname:       "api.example.com",
+			tcp:         true,
+			wantHealthy: true,
+			wantStatus:  "NOERROR, 2 answers",
+		},
+		{
+			name:        "SERVFAIL is unhealthy",
+			qname:       "broken.example.com",
+			wantHealthy: false,
+			wantStatus:  "SERVFAIL, 0 answers",
+		},
+		{
+			name:        "NXDOMAIN is unhealthy by default",
+			qname:       "missing.example.com",
+			wantHealthy: false,
+			wantStatus:  "NXDOMAIN, 0 answers",
+		},
+		{
+			name:        "NXDOMAIN accepted when configured",
+			qname:       "missing.example.com",
+			rcodes:      []string{"NOERROR", "nxdomain"},
+			wantHealthy: true,
+			wantStatus:  "NXDOMAIN, 0 answers",
+		},
+		{
+			name:        "expected answer found",
+			qname:       "api.example.com",
+			expect:      "10.0.0.2",
+			wantHealthy: true,
+			wantStatus:  "NOERROR, 2 answers",
+		},
+		{
+			name:        "expected answer missing",
+			qname:       "api.example.com",
+			expect:      "10.0.0.3",
+			wantHealthy: false,
+			wantStatus:  `NOERROR, 2 answers, expected answer "10.0.0.3" not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var server string
+			if tt.tcp {
+				server = startFakeDNSServerTCP(t, answer)
+			} else {
+				server = startFakeDNSServerUDP(t, answer)
+			}
+
+			checker := NewDNSChecker(server, tt.qname, "A")
+			checker.TCP = tt.tcp
+			checker.RCodes = tt.rcodes
+			checker.Expect = tt.expect
+			checker.Retry = SingleAttemptPolicy(time.Second)
+
+			res, err := checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+
+			if res.Healthy() != tt.wantHealthy {
+				t.Errorf("Check() healthy = %v, want %v", res.Healthy(), tt.wantHealthy)
+			}
+
+			if res.Status != tt.wantStatus {
+				t.Errorf("Check() status = %q, want %q", res.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestDNSChecker_Check_Retries(t *testing.T) {
+	var queryCount atomic.Int32
+
+	server := startFakeDNSServerUDP(t, func(q dnsmessage.Question) (dnsmessage.RCode, []string) {
+		if queryCount.Add(1) == 1 {
+			return dnsmessage.RCodeServerFailure, nil
+		}
+		return dnsmessage.RCodeSuccess, []string{"10.0.0.1"}
+	})
+
+	checker := NewDNSChecker(server, "api.example.com", "A")
+	checker.Retry.Timeout = time.Second
+	checker.Retry.Delay = 10 * time.Millisecond
+
+	res, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if !res.Healthy() {
+		t.Errorf("Check() healthy = false, want true (status %q)", res.Status)
+	}
+
+	if queryCount.Load() != 2 {
+		t.Errorf("Expected 2 queries, got %d", queryCount.Load())
+	}
+}
+
+func TestDNSChecker_Check_Timeout(t *testing.T) {
+	// A UDP socket that never answers
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer pc.Close() //nolint:errcheck
+
+	checker := NewDNSChecker(pc.LocalAddr().String(), "api.example.com", "A")
+	checker.Retry = RetryPolicy{Attempts: 2, Timeout: 100 * time.Millisecond, Delay: 10 * time.Millisecond}
+
+	res, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if res.Healthy() {
+		t.Errorf("Check() healthy = true, want false")
+	}
+
+	if !strings.Contains(res.Status, "timeout") {
+		t.Errorf("Check() status = %q, want timeout error", res.Status)
+	}
+
+	if res.Class != gslb.ErrorTimeout || res.Attempts != 2 {
+		t.Errorf("Check() class = %q after %d attempts, want %q after 2", res.Class, res.Attempts, gslb.ErrorTimeout)
+	}
+}
+
+func TestDNSChecker_Check_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker *DNSChecker
+	}{
+		{
+			name:    "server without port",
+			checker: NewDNSChecker("127.0.0.1", "example.com", "A"),
+		},
+		{
+			name:    "unsupported type",
+			checker: NewDNSChecker("127.0.0.1:53", "example.com", "AXFR"),
+		},
+		{
+			name:    "unsupported rcode",
+			checker: &DNSChecker{Server: "127.0.0.1:53", Name: "example.com", Type: "A", RCodes: []string{"BOGUS"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.checker.Check(context.Background()); err == nil {
+				t.Errorf("Check() expected error, got nil")
+			}
+		})
+	}
+}
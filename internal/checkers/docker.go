@@ -0,0 +1,224 @@
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// DefaultDockerHost is the socket of a local Docker Engine.
+const DefaultDockerHost = "unix:///var/run/docker.sock"
+
+// DockerChecker inspects a container through the Docker Engine API. A
+// running container is healthy according to its HEALTHCHECK, or healthy if
+// it has none.
+type DockerChecker struct {
+	// Host is the Engine API address in DOCKER_HOST form, e.g.
+	// unix:///var/run/docker.sock or tcp://10.0.0.101:2376.
+	Host      string
+	Container string
+
+	// TLSFiles enables TLS for tcp hosts, usually with a client
+	// certificate.
+	TLSFiles *TLSFiles
+
+	Retry RetryPolicy
+}
+
+func NewDockerChecker(host, container string) *DockerChecker {
+	return &DockerChecker{
+		Host:      host,
+		Container: container,
+		Retry:     SingleAttemptPolicy(5 * time.Second),
+	}
+}
+
+// Validate reports configuration errors of the checker.
+func (c *DockerChecker) Validate() error {
+	if c.Container == "" {
+		return fmt.Errorf("container must be set")
+	}
+
+	_, _, err := c.endpoint()
+
+	return err
+}
+
+// endpoint returns the network and address to dial and the URL scheme.
+func (c *DockerChecker) endpoint() (string, string, error) {
+	scheme, address, ok := strings.Cut(c.Host, "://")
+	if !ok {
+		return "", "", fmt.Errorf("invalid Docker host %q, expected unix:// or tcp://", c.Host)
+	}
+
+	switch scheme {
+	case "unix":
+		if address == "" {
+			return "", "", fmt.Errorf("invalid Docker host %q, missing socket path", c.Host)
+		}
+
+		return "unix", address, nil
+	case "tcp":
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return "", "", fmt.Errorf("invalid Docker host %q: %w", c.Host, err)
+		}
+
+		return "tcp", address, nil
+	default:
+		return "", "", fmt.Errorf("unsupported Docker host scheme %q", scheme)
+	}
+}
+
+// Check implements gslb.HealthChecker.
+func (c *DockerChecker) Check(ctx context.Context) (gslb.Result, error) {
+	if err := c.Validate(); err != nil {
+		return gslb.Result{}, err
+	}
+
+	network, address, _ := c.endpoint()
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, address)
+		},
+	}
+	defer transport.CloseIdleConnections()
+
+	// The host part of the URL is only used for the Host header on sockets
+	u := url.URL{
+		Scheme: "http",
+		Host:   "docker",
+		Path:   "/containers/" + url.PathEscape(c.Container) + "/json",
+	}
+
+	if network == "tcp" {
+		u.Host = address
+
+		if c.TLSFiles != nil {
+			tlsConfig, err := newTLSConfig(false, "", 0, c.TLSFiles)
+			if err != nil {
+				return gslb.Result{}, err
+			}
+
+			transport.TLSClientConfig = tlsConfig
+			u.Scheme = "https"
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return gslb.Result{}, fmt.Errorf("creating request: %w", err)
+	}
+
+	client := &http.Client{Transport: transport}
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		start := time.Now()
+
+		resp, err := client.Do(req.Clone(ctx))
+		if err != nil {
+			return failure(errorClass(err), err.Error(), time.Since(start))
+		}
+		defer resp.Body.Close() //nolint:errcheck
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			return failure(gslb.ErrorResponse, fmt.Sprintf("container %s not found", c.Container), time.Since(start))
+		default:
+			return failure(gslb.ErrorStatus, fmt.Sprintf("inspecting container %s: %s", c.Container, resp.Status), time.Since(start))
+		}
+
+		var info dockerContainer
+		err = json.NewDecoder(io.LimitReader(resp.Body, maxBodySize)).Decode(&info)
+		latency := time.Since(start)
+
+		if err != nil {
+			return failure(gslb.ErrorResponse, fmt.Sprintf("decoding response: %s", err), latency)
+		}
+
+		res := c.evaluate(info.State)
+		res.Latency = latency
+
+		return res
+	})
+}
+
+type dockerContainer struct {
+	State dockerState `json:"State"`
+}
+
+type dockerState struct {
+	Status     string `json:"Status"`
+	Running    bool   `json:"Running"`
+	Paused     bool   `json:"Paused"`
+	Restarting bool   `json:"Restarting"`
+	ExitCode   int    `json:"ExitCode"`
+	Health     *struct {
+		Status        string `json:"Status"`
+		FailingStreak int    `json:"FailingStreak"`
+		Log           []struct {
+			ExitCode int    `json:"ExitCode"`
+			Output   string `json:"Output"`
+		} `json:"Log"`
+	} `json:"Health"`
+}
+
+// evaluate maps the container state to a result. A container whose
+// HEALTHCHECK is still starting is unknown, as it is not known to serve yet.
+func (c *DockerChecker) evaluate(s dockerState) gslb.Result {
+	details := map[string]string{"status": s.Status}
+
+	switch {
+	case !s.Running:
+		res := failure(gslb.ErrorResponse, fmt.Sprintf("container %s is %s (exit code %d)", c.Container, s.Status, s.ExitCode), 0)
+		res.Details = details
+
+		return res
+	case s.Paused, s.Restarting:
+		res := failure(gslb.ErrorResponse, fmt.Sprintf("container %s is %s", c.Container, s.Status), 0)
+		res.Details = details
+
+		return res
+	case s.Health == nil || s.Health.Status == "" || s.Health.Status == "none":
+		return gslb.Result{
+			State:   gslb.StateHealthy,
+			Status:  fmt.Sprintf("container %s is running without healthcheck", c.Container),
+			Details: details,
+		}
+	}
+
+	details["health"] = s.Health.Status
+	details["failing_streak"] = strconv.Itoa(s.Health.FailingStreak)
+
+	status := fmt.Sprintf("container %s is %s", c.Container, s.Health.Status)
+
+	switch s.Health.Status {
+	case "healthy":
+		return gslb.Result{State: gslb.StateHealthy, Status: status, Details: details}
+	case "starting":
+		return gslb.Result{State: gslb.StateUnknown, Class: gslb.ErrorResponse, Status: status, Details: details}
+	default:
+		// Report why the last healthcheck failed
+		if n := len(s.Health.Log); n > 0 {
+			if output, _, _ := strings.Cut(strings.TrimSpace(s.Health.Log[n-1].Output), "\n"); output != "" {
+				status += ": " + output
+			}
+		}
+
+		res := failure(gslb.ErrorResponse, status, 0)
+		res.Details = details
+
+		return res
+	}
+}
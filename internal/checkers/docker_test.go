@@ -0,0 +1,129 @@
+package checkers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// startDockerServer serves container inspect responses of a fake Docker
+// Engine API on a unix socket and returns its DOCKER_HOST address.
+func startDockerServer(t *testing.T, containers map[string]string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "docker.sock")
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := strings.CutPrefix(r.URL.Path, "/containers/")
+		name, ok2 := strings.CutSuffix(name, "/json")
+
+		resp, found := containers[name]
+		switch {
+		case !ok || !ok2 || r.Method != http.MethodGet:
+			http.NotFound(w, r)
+		case !found:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"No such container: ` + name + `"}`)) //nolint:errcheck
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(resp)) //nolint:errcheck
+		}
+	}))
+	server.Listener = ln
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return "unix://" + path
+}
+
+func TestDockerChecker_Check(t *testing.T) {
+	host := startDockerServer(t, map[string]string{
+		"api": `{"State":{"Status":"running","Running":true,"Health":{"Status":"healthy","FailingStreak":0}}}`,
+		"sick": `{"State":{"Status":"running","Running":true,"Health":{"Status":"unhealthy","FailingStreak":3,
+			"Log":[{"ExitCode":1,"Output":"old"},{"ExitCode":1,"Output":"curl: (7) Failed to connect\n"}]}}}`,
+		"booting": `{"State":{"Status":"running","Running":true,"Health":{"Status":"starting"}}}`,
+		"plain":   `{"State":{"Status":"running","Running":true}}`,
+		"stopped": `{"State":{"Status":"exited","Running":false,"ExitCode":137}}`,
+		"paused":  `{"State":{"Status":"paused","Running":true,"Paused":true,"Health":{"Status":"healthy"}}}`,
+		"broken":  `{"State":`,
+	})
+
+	tests := []struct {
+		container  string
+		wantState  gslb.State
+		wantStatus string
+	}{
+		{"api", gslb.StateHealthy, "container api is healthy"},
+		{"sick", gslb.StateUnhealthy, "container sick is unhealthy: curl: (7) Failed to connect"},
+		{"booting", gslb.StateUnknown, "container booting is starting"},
+		{"plain", gslb.StateHealthy, "container plain is running without healthcheck"},
+		{"stopped", gslb.StateUnhealthy, "container stopped is exited (exit code 137)"},
+		{"paused", gslb.StateUnhealthy, "container paused is paused"},
+		{"missing", gslb.StateUnhealthy, "container missing not found"},
+		{"broken", gslb.StateUnhealthy, "decoding response: unexpected EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.container, func(t *testing.T) {
+			res, err := NewDockerChecker(host, tt.container).Check(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState {
+				t.Errorf("expected %s, got %s: %s", tt.wantState, res.State, res.Status)
+			}
+			if res.Status != tt.wantStatus {
+				t.Errorf("expected status %q, got %q", tt.wantStatus, res.Status)
+			}
+		})
+	}
+}
+
+func TestDockerChecker_TCP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"State":{"Status":"running","Running":true,"Health":{"Status":"healthy"}}}`)) //nolint:errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	host := "tcp://" + strings.TrimPrefix(server.URL, "http://")
+
+	res, err := NewDockerChecker(host, "api").Check(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.State != gslb.StateHealthy || res.Details["health"] != "healthy" {
+		t.Errorf("expected healthy, got %s: %s", res.State, res.Status)
+	}
+}
+
+func TestDockerChecker_Validate(t *testing.T) {
+	tests := []struct {
+		host      string
+		container string
+	}{
+		{"unix:///var/run/docker.sock", ""},
+		{"/var/run/docker.sock", "api"},
+		{"unix://", "api"},
+		{"tcp://10.0.0.101", "api"},
+		{"ssh://user@host", "api"},
+	}
+
+	for _, tt := range tests {
+		if err := NewDockerChecker(tt.host, tt.container).Validate(); err == nil {
+			t.Errorf("expected error for host %q and container %q", tt.host, tt.container)
+		}
+	}
+}
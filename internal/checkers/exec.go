@@ -0,0 +1,144 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// maxOutputSize limits how much command output is kept for the status.
+const maxOutputSize = 4096
+
+// Nagios plugin exit codes
+const (
+	nagiosOK       = 0
+	nagiosWarning  = 1
+	nagiosCritical = 2
+)
+
+// ExecChecker runs a command following the Nagios plugin conventions: exit
+// code 0 is healthy, 1 degraded, 2 unhealthy and anything else unknown. The
+// first line of its output becomes the status.
+type ExecChecker struct {
+	Command string
+	Args    []string
+
+	// Env holds additional KEY=value pairs. The command does not inherit
+	// the environment of the switcher, which contains the OpnSense
+	// credentials, except for PATH.
+	Env []string
+
+	// Retry limits every run through its timeout, the whole process group
+	// is killed when it expires.
+	Retry RetryPolicy
+}
+
+func NewExecChecker(command string, args ...string) *ExecChecker {
+	return &ExecChecker{
+		Command: command,
+		Args:    args,
+		Retry:   SingleAttemptPolicy(10 * time.Second),
+	}
+}
+
+// Check implements gslb.HealthChecker.
+func (c *ExecChecker) Check(ctx context.Context) (gslb.Result, error) {
+	// A missing command is a misconfiguration, not an unhealthy target
+	path, err := exec.LookPath(c.Command)
+	if err != nil {
+		return gslb.Result{}, err
+	}
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		return c.run(ctx, path)
+	})
+}
+
+func (c *ExecChecker) run(ctx context.Context, path string) gslb.Result {
+	var out limitedBuffer
+
+	cmd := exec.CommandContext(ctx, path, c.Args...)
+	cmd.Env = append([]string{"PATH=" + os.Getenv("PATH")}, c.Env...)
+	cmd.Stdout = &out
+	setProcessGroup(cmd)
+
+	// Do not wait for children that keep the output open after a kill
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	latency := time.Since(start)
+
+	if ctx.Err() != nil {
+		return failure(gslb.ErrorTimeout, fmt.Sprintf("%s: %s", c.Command, ctx.Err()), latency)
+	}
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return failure(gslb.ErrorUnknown, fmt.Sprintf("running %s: %s", c.Command, err), latency)
+	}
+
+	code := cmd.ProcessState.ExitCode()
+	status, perfdata := parsePluginOutput(out.Bytes())
+	if status == "" {
+		status = cmd.ProcessState.String()
+	}
+
+	res := gslb.Result{
+		Status:  status,
+		Latency: latency,
+		Details: map[string]string{"exit_code": strconv.Itoa(code)},
+	}
+
+	if perfdata != "" {
+		res.Details["perfdata"] = perfdata
+	}
+
+	switch code {
+	case nagiosOK:
+		res.State = gslb.StateHealthy
+	case nagiosWarning:
+		res.State = gslb.StateDegraded
+	case nagiosCritical:
+		res.State = gslb.StateUnhealthy
+		res.Class = gslb.ErrorResponse
+	default:
+		// Includes 3 (UNKNOWN) and commands killed by a signal
+		res.State = gslb.StateUnknown
+		res.Class = gslb.ErrorUnknown
+	}
+
+	return res
+}
+
+// parsePluginOutput returns the first line of plugin output, split into the
+// text and the performance data following a "|".
+func parsePluginOutput(out []byte) (string, string) {
+	line, _, _ := bytes.Cut(out, []byte("\n"))
+	text, perfdata, _ := strings.Cut(string(line), "|")
+
+	return strings.TrimSpace(text), strings.TrimSpace(perfdata)
+}
+
+// limitedBuffer keeps the first maxOutputSize bytes written to it and
+// discards the rest, so a chatty command cannot make the checker buffer
+// endlessly.
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if n := maxOutputSize - b.Len(); n > 0 {
+		b.Buffer.Write(p[:min(n, len(p))])
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package checkers
+
+import "os/exec"
+
+// setProcessGroup is a no-op without process groups, only the command
+// itself is killed on cancellation.
+func setProcessGroup(cmd *exec.Cmd) {}
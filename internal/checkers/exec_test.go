@@ -0,0 +1,133 @@
+//go:build unix
+
+package checkers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+func TestExecChecker_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		env        []string
+		wantState  gslb.State
+		wantStatus string
+		wantCode   string
+	}{
+		{
+			name:       "ok",
+			script:     "echo 'HTTP OK - 200 in 0.1s|time=0.1s'; echo 'more details'",
+			wantState:  gslb.StateHealthy,
+			wantStatus: "HTTP OK - 200 in 0.1s",
+			wantCode:   "0",
+		},
+		{
+			name:       "warning",
+			script:     "echo 'DISK WARNING - 85% used'; exit 1",
+			wantState:  gslb.StateDegraded,
+			wantStatus: "DISK WARNING - 85% used",
+			wantCode:   "1",
+		},
+		{
+			name:       "critical",
+			script:     "echo 'PROCS CRITICAL - 0 processes'; exit 2",
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: "PROCS CRITICAL - 0 processes",
+			wantCode:   "2",
+		},
+		{
+			name:       "unknown",
+			script:     "echo 'UNKNOWN - invalid option'; exit 3",
+			wantState:  gslb.StateUnknown,
+			wantStatus: "UNKNOWN - invalid option",
+			wantCode:   "3",
+		},
+		{
+			name:       "other exit code is unknown",
+			script:     "exit 127",
+			wantState:  gslb.StateUnknown,
+			wantStatus: "exit status 127",
+			wantCode:   "127",
+		},
+		{
+			name:       "environment",
+			script:     `echo "$CHECK_LEVEL ${OPNSENSE_AUTH:-unset}"`,
+			env:        []string{"CHECK_LEVEL=deep"},
+			wantState:  gslb.StateHealthy,
+			wantStatus: "deep unset",
+			wantCode:   "0",
+		},
+	}
+
+	t.Setenv("OPNSENSE_AUTH", "key:secret")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewExecChecker("sh", "-c", tt.script)
+			checker.Env = tt.env
+
+			res, err := checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState {
+				t.Errorf("Check() state = %v, want %v", res.State, tt.wantState)
+			}
+			if res.Status != tt.wantStatus {
+				t.Errorf("Check() status = %q, want %q", res.Status, tt.wantStatus)
+			}
+			if res.Details["exit_code"] != tt.wantCode {
+				t.Errorf("Check() exit_code = %q, want %q", res.Details["exit_code"], tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestExecChecker_Check_Perfdata(t *testing.T) {
+	checker := NewExecChecker("sh", "-c", "echo 'OK - load 0.5 | load1=0.5;5;10'")
+
+	res, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if res.Details["perfdata"] != "load1=0.5;5;10" {
+		t.Errorf("Check() perfdata = %q, want %q", res.Details["perfdata"], "load1=0.5;5;10")
+	}
+}
+
+func TestExecChecker_Check_Timeout(t *testing.T) {
+	// The background child keeps stdout open, so the check only returns
+	// before the WaitDelay expires if the whole process group is killed.
+	checker := NewExecChecker("sh", "-c", "sleep 30 & sleep 30")
+	checker.Retry = SingleAttemptPolicy(100 * time.Millisecond)
+
+	start := time.Now()
+
+	res, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 800*time.Millisecond {
+		t.Errorf("Check() took %v, process group was not killed", elapsed)
+	}
+
+	if res.State != gslb.StateUnhealthy || res.Class != gslb.ErrorTimeout {
+		t.Errorf("Check() = %v/%q, want unhealthy timeout (status %q)", res.State, res.Class, res.Status)
+	}
+}
+
+func TestExecChecker_Check_CommandNotFound(t *testing.T) {
+	checker := NewExecChecker("/nonexistent/check_http")
+
+	if _, err := checker.Check(context.Background()); err == nil {
+		t.Errorf("Check() expected error for missing command, got nil")
+	}
+}
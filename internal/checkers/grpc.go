@@ -0,0 +1,93 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+type GRPCChecker struct {
+	Address       string
+	Service       string
+	TLS           bool
+	SkipTLSVerify bool
+	Retry         RetryPolicy
+}
+
+func NewGRPCChecker(address string, useTLS, skipTLSVerify bool) *GRPCChecker {
+	return &GRPCChecker{
+		Address:       address,
+		TLS:           useTLS,
+		SkipTLSVerify: skipTLSVerify,
+		Retry:         SingleAttemptPolicy(5 * time.Second),
+	}
+}
+
+// Check implements gslb.HealthChecker.
+func (c *GRPCChecker) Check(ctx context.Context) (gslb.Result, error) {
+	// Validate address before attempting to connect
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return gslb.Result{}, err
+	}
+
+	// Configure transport credentials with optional TLS verification skip
+	creds := insecure.NewCredentials()
+	if c.TLS {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: c.SkipTLSVerify})
+	}
+
+	conn, err := grpc.NewClient(c.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return gslb.Result{}, fmt.Errorf("creating gRPC client: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	client := healthpb.NewHealthClient(conn)
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		start := time.Now()
+
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: c.Service})
+		if err != nil {
+			// RPC errors mean service is unhealthy, not an internal error
+			switch status.Code(err) {
+			case codes.Unimplemented:
+				return failure(gslb.ErrorResponse, "health service not implemented", time.Since(start))
+			case codes.NotFound:
+				return failure(gslb.ErrorResponse, fmt.Sprintf("unknown service %q", c.Service), time.Since(start))
+			default:
+				return failure(grpcErrorClass(err), err.Error(), time.Since(start))
+			}
+		}
+
+		// Only SERVING is healthy, NOT_SERVING and UNKNOWN are not
+		if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return failure(gslb.ErrorStatus, resp.GetStatus().String(), time.Since(start))
+		}
+
+		return gslb.Result{State: gslb.StateHealthy, Status: resp.GetStatus().String(), Latency: time.Since(start)}
+	})
+}
+
+// grpcErrorClass classifies RPC errors by their status code.
+func grpcErrorClass(err error) gslb.ErrorClass {
+	switch status.Code(err) {
+	case codes.DeadlineExceeded:
+		return gslb.ErrorTimeout
+	case codes.Unavailable:
+		return gslb.ErrorConnection
+	default:
+		return gslb.ErrorUnknown
+	}
+}
@@ -0,0 +1,172 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// startGRPCHealthServer starts a gRPC server exposing the standard health
+// service and returns its address together with the health server to control
+// the reported status.
+func startGRPCHealthServer(t *testing.T, opts ...grpc.ServerOption) (string, *health.Server) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	srv := grpc.NewServer(opts...)
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+
+	go srv.Serve(ln) //nolint:errcheck
+	t.Cleanup(srv.Stop)
+
+	return ln.Addr().String(), hs
+}
+
+func TestGRPCChecker_Check(t *testing.T) {
+	addr, hs := startGRPCHealthServer(t)
+	hs.SetServingStatus("api", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("worker", healthpb.HealthCheckResponse_NOT_SERVING)
+	hs.SetServingStatus("batch", healthpb.HealthCheckResponse_UNKNOWN)
+
+	tests := []struct {
+		name          string
+		service       string
+		wantHealthy   bool
+		wantStatusMsg string
+	}{
+		{
+			name:          "overall server status",
+			service:       "",
+			wantHealthy:   true,
+			wantStatusMsg: "SERVING",
+		},
+		{
+			name:          "serving service",
+			service:       "api",
+			wantHealthy:   true,
+			wantStatusMsg: "SERVING",
+		},
+		{
+			name:          "not serving service",
+			service:       "worker",
+			wantHealthy:   false,
+			wantStatusMsg: "NOT_SERVING",
+		},
+		{
+			name:          "unknown status",
+			service:       "batch",
+			wantHealthy:   false,
+			wantStatusMsg: "UNKNOWN",
+		},
+		{
+			name:          "unregistered service",
+			service:       "missing",
+			wantHealthy:   false,
+			wantStatusMsg: `unknown service "missing"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewGRPCChecker(addr, false, false)
+			checker.Service = tt.service
+
+			res, err := checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+
+			if res.Healthy() != tt.wantHealthy {
+				t.Errorf("Check() healthy = %v, want %v", res.Healthy(), tt.wantHealthy)
+			}
+
+			if res.Status != tt.wantStatusMsg {
+				t.Errorf("Check() status = %q, want %q", res.Status, tt.wantStatusMsg)
+			}
+		})
+	}
+}
+
+func TestGRPCChecker_Check_TLS(t *testing.T) {
+	cert := newTestCertificate(t, time.Now().Add(24*time.Hour))
+	creds := credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}})
+	addr, _ := startGRPCHealthServer(t, grpc.Creds(creds))
+
+	tests := []struct {
+		name          string
+		skipTLSVerify bool
+		wantHealthy   bool
+	}{
+		{
+			name:          "self-signed certificate rejected",
+			skipTLSVerify: false,
+			wantHealthy:   false,
+		},
+		{
+			name:          "self-signed certificate accepted with skip verify",
+			skipTLSVerify: true,
+			wantHealthy:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewGRPCChecker(addr, true, tt.skipTLSVerify)
+			checker.Retry.Timeout = 2 * time.Second
+
+			res, err := checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+
+			if res.Healthy() != tt.wantHealthy {
+				t.Errorf("Check() healthy = %v, want %v (status %q)", res.Healthy(), tt.wantHealthy, res.Status)
+			}
+		})
+	}
+}
+
+func TestGRPCChecker_Check_NoHealthService(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	srv := grpc.NewServer()
+	go srv.Serve(ln) //nolint:errcheck
+	defer srv.Stop()
+
+	checker := NewGRPCChecker(ln.Addr().String(), false, false)
+	res, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if res.Healthy() {
+		t.Errorf("Check() healthy = true, want false")
+	}
+
+	if res.Status != "health service not implemented" {
+		t.Errorf("Check() status = %q, want %q", res.Status, "health service not implemented")
+	}
+}
+
+func TestGRPCChecker_Check_InvalidAddress(t *testing.T) {
+	checker := NewGRPCChecker("missing-port", false, false)
+
+	if _, err := checker.Check(context.Background()); err == nil {
+		t.Errorf("Check() expected error for invalid address, got nil")
+	}
+}
@@ -0,0 +1,244 @@
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// maxHAProxyStatSize limits the size of the show stat output read.
+const maxHAProxyStatSize = 1 << 20
+
+// HAProxyChecker reads the server states of a backend from the HAProxy
+// runtime API using "show stat".
+//
+// The target is unhealthy if fewer than MinUp servers of Backend are UP and
+// degraded if enough are UP but others are not.
+type HAProxyChecker struct {
+	// Address is the runtime API socket, either a unix socket path or a
+	// TCP host:port.
+	Address string
+	Backend string
+
+	// MinUp is the number of servers that must be UP, 1 if zero.
+	MinUp int
+
+	Retry RetryPolicy
+}
+
+func NewHAProxyChecker(address, backend string) *HAProxyChecker {
+	return &HAProxyChecker{
+		Address: address,
+		Backend: backend,
+		MinUp:   1,
+		Retry:   SingleAttemptPolicy(5 * time.Second),
+	}
+}
+
+// network returns the network of Address, unix for paths.
+func (c *HAProxyChecker) network() (string, string) {
+	if path, ok := strings.CutPrefix(c.Address, "unix:"); ok {
+		return "unix", path
+	}
+
+	if strings.HasPrefix(c.Address, "/") {
+		return "unix", c.Address
+	}
+
+	return "tcp", c.Address
+}
+
+// Validate reports configuration errors of the checker.
+func (c *HAProxyChecker) Validate() error {
+	if c.Backend == "" {
+		return fmt.Errorf("backend must be set")
+	}
+
+	if c.MinUp < 0 {
+		return fmt.Errorf("minimum of UP servers must not be negative")
+	}
+
+	if network, address := c.network(); network == "tcp" {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Check implements gslb.HealthChecker.
+func (c *HAProxyChecker) Check(ctx context.Context) (gslb.Result, error) {
+	if err := c.Validate(); err != nil {
+		return gslb.Result{}, err
+	}
+
+	return c.Retry.Run(ctx, c.check)
+}
+
+func (c *HAProxyChecker) check(ctx context.Context) gslb.Result {
+	start := time.Now()
+
+	network, address := c.network()
+
+	conn, err := dialContext(ctx, network, address)
+	if err != nil {
+		return failure(errorClass(err), err.Error(), time.Since(start))
+	}
+	defer conn.Close() //nolint:errcheck
+
+	// Without interactive mode HAProxy closes the connection after the reply
+	if _, err := io.WriteString(conn, "show stat\n"); err != nil {
+		return failure(errorClass(err), err.Error(), time.Since(start))
+	}
+
+	servers, err := parseHAProxyStat(io.LimitReader(conn, maxHAProxyStatSize), c.Backend)
+	latency := time.Since(start)
+
+	if err != nil {
+		return failure(haproxyErrorClass(err), fmt.Sprintf("show stat: %s", err), latency)
+	}
+
+	if len(servers) == 0 {
+		return failure(gslb.ErrorResponse, fmt.Sprintf("backend %s has no servers", c.Backend), latency)
+	}
+
+	minUp := max(c.MinUp, 1)
+
+	var up int
+	var notUp []string
+	for _, s := range servers {
+		if haproxyServerUp(s.status) {
+			up++
+		} else {
+			notUp = append(notUp, s.name+" "+s.status)
+		}
+	}
+
+	res := gslb.Result{
+		State:   gslb.StateHealthy,
+		Latency: latency,
+		Status:  fmt.Sprintf("%d of %d servers UP in backend %s", up, len(servers), c.Backend),
+		Details: map[string]string{
+			"up":     strconv.Itoa(up),
+			"total":  strconv.Itoa(len(servers)),
+			"min_up": strconv.Itoa(minUp),
+		},
+	}
+
+	switch {
+	case up < minUp:
+		res.State = gslb.StateUnhealthy
+		res.Class = gslb.ErrorResponse
+		res.Status += fmt.Sprintf(", %d required", minUp)
+	case len(notUp) > 0:
+		res.State = gslb.StateDegraded
+	}
+
+	if len(notUp) > 0 {
+		res.Status += ": " + joinLimited(notUp)
+	}
+
+	return res
+}
+
+// haproxyError is an unexpected reply of the runtime API.
+type haproxyError string
+
+func (e haproxyError) Error() string {
+	return string(e)
+}
+
+func haproxyErrorClass(err error) gslb.ErrorClass {
+	var replyErr haproxyError
+	if errors.As(err, &replyErr) {
+		return gslb.ErrorResponse
+	}
+
+	return errorClass(err)
+}
+
+type haproxyServer struct {
+	name   string
+	status string
+}
+
+// parseHAProxyStat parses the CSV output of show stat and returns the
+// servers of backend, without the FRONTEND and BACKEND summary rows.
+func parseHAProxyStat(r io.Reader, backend string) ([]haproxyServer, error) {
+	br := bufio.NewReader(r)
+
+	// The header line is prefixed with "# "
+	header, err := br.ReadString('\n')
+	if err != nil && header == "" {
+		return nil, err
+	}
+
+	rest, ok := strings.CutPrefix(header, "# ")
+	if !ok {
+		return nil, haproxyError(fmt.Sprintf("unexpected reply %q", strings.TrimSpace(header)))
+	}
+
+	cr := csv.NewReader(io.MultiReader(strings.NewReader(rest), br))
+	cr.FieldsPerRecord = -1
+
+	records, err := cr.ReadAll()
+
+	var parseErr *csv.ParseError
+	switch {
+	case errors.As(err, &parseErr):
+		return nil, haproxyError(fmt.Sprintf("parsing CSV: %s", err))
+	case err != nil:
+		return nil, err
+	case len(records) == 0:
+		return nil, haproxyError("empty reply")
+	}
+
+	columns := records[0]
+	pxname := slices.Index(columns, "pxname")
+	svname := slices.Index(columns, "svname")
+	status := slices.Index(columns, "status")
+
+	if pxname < 0 || svname < 0 || status < 0 {
+		return nil, haproxyError("missing pxname, svname or status column")
+	}
+
+	var found bool
+	var servers []haproxyServer
+
+	for _, rec := range records[1:] {
+		if len(rec) <= max(pxname, svname, status) || rec[pxname] != backend {
+			continue
+		}
+
+		switch rec[svname] {
+		case "BACKEND":
+			found = true
+		case "FRONTEND":
+		default:
+			servers = append(servers, haproxyServer{name: rec[svname], status: rec[status]})
+		}
+	}
+
+	if !found {
+		return nil, haproxyError(fmt.Sprintf("backend %s not found", backend))
+	}
+
+	return servers, nil
+}
+
+// haproxyServerUp reports whether a server status counts as UP. Servers going
+// down ("UP 1/3") still serve traffic, as do servers without health checks.
+func haproxyServerUp(status string) bool {
+	return status == "UP" || strings.HasPrefix(status, "UP ") || status == "no check"
+}
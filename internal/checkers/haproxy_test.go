@@ -0,0 +1,168 @@
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+const haproxyStat = `# pxname,svname,qcur,qmax,scur,smax,slim,stot,bin,bout,dreq,dresp,ereq,econ,eresp,wretr,wredis,status,weight
+http-in,FRONTEND,,,1,2,3000,10,0,0,0,0,0,,,,,OPEN,
+api,web1,0,0,0,1,,5,0,0,,0,,0,0,0,0,UP,1
+api,web2,0,0,0,1,,5,0,0,,0,,0,0,0,0,UP 1/3,1
+api,web3,0,0,0,1,,5,0,0,,0,,0,0,0,0,DOWN,1
+api,web4,0,0,0,1,,5,0,0,,0,,0,0,0,0,MAINT,1
+api,BACKEND,0,0,0,1,300,15,0,0,0,0,,0,0,0,0,UP,2
+static,s1,0,0,0,1,,5,0,0,,0,,0,0,0,0,no check,1
+static,BACKEND,0,0,0,1,300,15,0,0,0,0,,0,0,0,0,UP,1
+empty,BACKEND,0,0,0,1,300,15,0,0,0,0,,0,0,0,0,DOWN,0
+
+`
+
+// serveHAProxyStat answers show stat like the HAProxy runtime API.
+func serveHAProxyStat(reply string) func(net.Conn) {
+	return func(conn net.Conn) {
+		cmd, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		if cmd != "show stat\n" {
+			reply = "Unknown command.\n"
+		}
+
+		io.WriteString(conn, reply) //nolint:errcheck
+	}
+}
+
+func TestHAProxyChecker_Check(t *testing.T) {
+	addr := startTCPServer(t, serveHAProxyStat(haproxyStat))
+
+	tests := []struct {
+		name       string
+		backend    string
+		minUp      int
+		wantState  gslb.State
+		wantClass  gslb.ErrorClass
+		wantStatus string
+	}{
+		{
+			name:       "enough servers up",
+			backend:    "api",
+			minUp:      2,
+			wantState:  gslb.StateDegraded,
+			wantStatus: "2 of 4 servers UP in backend api: web3 DOWN, web4 MAINT",
+		},
+		{
+			name:       "too few servers up",
+			backend:    "api",
+			minUp:      3,
+			wantState:  gslb.StateUnhealthy,
+			wantClass:  gslb.ErrorResponse,
+			wantStatus: "2 of 4 servers UP in backend api, 3 required: web3 DOWN, web4 MAINT",
+		},
+		{
+			name:       "all servers up",
+			backend:    "static",
+			wantState:  gslb.StateHealthy,
+			wantStatus: "1 of 1 servers UP in backend static",
+		},
+		{
+			name:       "no servers",
+			backend:    "empty",
+			wantState:  gslb.StateUnhealthy,
+			wantClass:  gslb.ErrorResponse,
+			wantStatus: "backend empty has no servers",
+		},
+		{
+			name:       "unknown backend",
+			backend:    "missing",
+			wantState:  gslb.StateUnhealthy,
+			wantClass:  gslb.ErrorResponse,
+			wantStatus: "show stat: backend missing not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewHAProxyChecker(addr, tt.backend)
+			if tt.minUp > 0 {
+				c.MinUp = tt.minUp
+			}
+
+			res, err := c.Check(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState || res.Class != tt.wantClass {
+				t.Errorf("expected %s (%q), got %s (%q): %s", tt.wantState, tt.wantClass, res.State, res.Class, res.Status)
+			}
+			if res.Status != tt.wantStatus {
+				t.Errorf("expected status %q, got %q", tt.wantStatus, res.Status)
+			}
+		})
+	}
+}
+
+func TestHAProxyChecker_UnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "haproxy.sock")
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() }) //nolint:errcheck
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close() //nolint:errcheck
+
+		serveHAProxyStat(haproxyStat)(conn)
+	}()
+
+	res, err := NewHAProxyChecker("unix:"+path, "static").Check(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.State != gslb.StateHealthy || res.Details["up"] != "1" {
+		t.Errorf("expected healthy with 1 server up, got %s: %s", res.State, res.Status)
+	}
+}
+
+func TestHAProxyChecker_Errors(t *testing.T) {
+	addr := startTCPServer(t, serveHAProxyStat("Permission denied\n"))
+
+	res, err := NewHAProxyChecker(addr, "api").Check(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.State != gslb.StateUnhealthy || !strings.Contains(res.Status, `unexpected reply "Permission denied"`) {
+		t.Errorf("expected unexpected reply, got %s: %s", res.State, res.Status)
+	}
+
+	if _, err := NewHAProxyChecker(addr, "").Check(context.Background()); err == nil {
+		t.Error("expected error for missing backend")
+	}
+
+	if _, err := NewHAProxyChecker("localhost", "api").Check(context.Background()); err == nil {
+		t.Error("expected error for address without port")
+	}
+
+	chk := NewHAProxyChecker(addr, "api")
+	chk.MinUp = -1
+	if err := chk.Validate(); err == nil {
+		t.Error("expected error for negative minimum of UP servers")
+	}
+}
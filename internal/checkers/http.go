@@ -1,79 +1,257 @@
 package checkers
 
 import (
+	"context"
 	"crypto/tls"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
 )
 
+// maxBodySize limits how much of the response body is read for assertions.
+const maxBodySize = 1 << 20
+
 type SimpleHTTPChecker struct {
 	URL           string
 	SkipTLSVerify bool
+
+	// Method defaults to GET, Headers are added to every request.
+	Method  string
+	Headers http.Header
+
+	// Host overrides the Host header and ServerName the TLS server name
+	// (SNI and certificate verification), e.g. when URL contains an IP.
+	Host       string
+	ServerName string
+
+	// TLSFiles optionally provides a custom CA bundle and a client
+	// certificate for mutual TLS, MinTLSVersion restricts the TLS versions.
+	TLSFiles      *TLSFiles
+	MinTLSVersion uint16
+
+	// StatusCodes lists the accepted status codes, 200-299 if empty.
+	StatusCodes StatusCodes
+
+	// BodyAssertions must all pass for a response with an accepted status
+	// code to count as healthy.
+	BodyAssertions []BodyAssertion
+
+	// Latency optionally turns slow responses unhealthy or degraded.
+	Latency *LatencyThreshold
+
+	Retry RetryPolicy
 }
 
 func NewSimpleHTTPChecker(url string, skipTLSVerify bool) *SimpleHTTPChecker {
 	return &SimpleHTTPChecker{
 		URL:           url,
 		SkipTLSVerify: skipTLSVerify,
+		Retry:         DefaultRetryPolicy(),
 	}
 }
 
-func (c *SimpleHTTPChecker) CheckHealth() (bool, string, error) {
-	const maxRetries = 3
-	const retryDelay = 10 * time.Second
+// CheckHealth reports only whether the target is healthy and its status.
+func (c *SimpleHTTPChecker) CheckHealth(ctx context.Context) (bool, string, error) {
+	res, err := c.Check(ctx)
+	if err != nil {
+		return false, "", err
+	}
+
+	return res.Healthy(), res.Status, nil
+}
 
+// Check implements gslb.HealthChecker.
+func (c *SimpleHTTPChecker) Check(ctx context.Context) (gslb.Result, error) {
 	// Validate URL before attempting request
 	_, err := url.Parse(c.URL)
 	if err != nil {
-		return false, "", err
+		return gslb.Result{}, err
 	}
 
-	// Configure HTTP client with optional TLS verification skip
-	transport := &http.Transport{}
-	if c.SkipTLSVerify {
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	method := c.Method
+	if method == "" {
+		method = http.MethodGet
 	}
 
-	client := &http.Client{
-		Timeout:   5 * time.Second,
-		Transport: transport,
+	req, err := http.NewRequest(method, c.URL, nil)
+	if err != nil {
+		return gslb.Result{}, fmt.Errorf("creating request: %w", err)
 	}
 
-	var lastStatus string
+	for name, values := range c.Headers {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
 
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		resp, err := client.Get(c.URL)
-		if err != nil {
-			lastStatus = err.Error()
+	if c.Host != "" {
+		req.Host = c.Host
+	}
 
-			// If this is not the last attempt, wait before retrying
-			if attempt < maxRetries {
-				time.Sleep(retryDelay)
-				continue
-			}
+	// Configure HTTP client with optional TLS verification skip
+	tlsConfig, err := newTLSConfig(c.SkipTLSVerify, c.ServerName, c.MinTLSVersion, c.TLSFiles)
+	if err != nil {
+		return gslb.Result{}, err
+	}
 
+	transport := &http.Transport{TLSClientConfig: tlsConfig}
+	defer transport.CloseIdleConnections()
+
+	// The retry policy limits each attempt through its context
+	client := &http.Client{Transport: transport}
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		start := time.Now()
+
+		resp, err := client.Do(req.Clone(ctx))
+		if err != nil {
 			// Connection errors mean service is unhealthy, not an internal error
-			return false, lastStatus, nil
+			return failure(errorClass(err), err.Error(), time.Since(start))
 		}
 		defer resp.Body.Close() //nolint:errcheck
 
-		// Return true for 2XX status codes (healthy), false otherwise (unhealthy)
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return true, resp.Status, nil
+		details := map[string]string{"status_code": strconv.Itoa(resp.StatusCode)}
+
+		// Accepted status codes are healthy, all others unhealthy
+		if !c.StatusCodes.Contains(resp.StatusCode) {
+			latency := time.Since(start)
+			res := failure(gslb.ErrorStatus, fmt.Sprintf("%s in %s", resp.Status, formatLatency(latency)), latency)
+			res.Details = details
+
+			return res
+		}
+
+		failures := c.checkBody(resp.Body)
+		latency := time.Since(start)
+
+		status := fmt.Sprintf("%s in %s", resp.Status, formatLatency(latency))
+		if len(failures) > 0 {
+			res := failure(gslb.ErrorResponse, status+": "+strings.Join(failures, "; "), latency)
+			res.Details = details
+
+			return res
 		}
 
-		lastStatus = resp.Status
+		res := gslb.Result{
+			State:   gslb.StateHealthy,
+			Status:  status,
+			Latency: latency,
+			Details: details,
+		}
+
+		// Only responses that pass all other checks are judged by latency
+		healthy, violation := c.Latency.Evaluate(latency)
+		if violation != "" {
+			res.Class = gslb.ErrorLatency
+			res.Status += ": " + violation
+
+			res.State = gslb.StateDegraded
+			if !healthy {
+				res.State = gslb.StateUnhealthy
+			}
+		}
 
-		// If this is not the last attempt and status is not healthy, retry
-		if attempt < maxRetries {
-			time.Sleep(retryDelay)
+		return res
+	})
+}
+
+// newTLSConfig creates the TLS configuration of HTTP based checks.
+func newTLSConfig(skipVerify bool, serverName string, minVersion uint16, files *TLSFiles) (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: skipVerify,
+		ServerName:         serverName,
+		MinVersion:         minVersion,
+	}
+
+	if files != nil {
+		if err := files.Apply(cfg); err != nil {
+			return nil, fmt.Errorf("loading TLS files: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// checkBody runs all body assertions and returns the failed ones.
+func (c *SimpleHTTPChecker) checkBody(r io.Reader) []string {
+	if len(c.BodyAssertions) == 0 {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r, maxBodySize))
+	if err != nil {
+		return []string{fmt.Sprintf("reading body: %s", err)}
+	}
+
+	var failures []string
+	for _, a := range c.BodyAssertions {
+		if msg := a.Check(body); msg != "" {
+			failures = append(failures, msg)
+		}
+	}
+
+	return failures
+}
+
+// StatusCodes is a set of HTTP status code ranges.
+type StatusCodes []StatusCodeRange
+
+type StatusCodeRange struct {
+	Min, Max int
+}
+
+// ParseStatusCodes parses a comma separated list of status codes and ranges,
+// e.g. "200-299,301,302".
+func ParseStatusCodes(s string) (StatusCodes, error) {
+	var codes StatusCodes
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
 			continue
 		}
 
-		return false, lastStatus, nil
+		lo, hi, isRange := strings.Cut(part, "-")
+
+		minCode, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q", part)
+		}
+
+		maxCode := minCode
+		if isRange {
+			if maxCode, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+				return nil, fmt.Errorf("invalid status code range %q", part)
+			}
+		}
+
+		if minCode < 100 || maxCode > 599 || minCode > maxCode {
+			return nil, fmt.Errorf("invalid status code range %q", part)
+		}
+
+		codes = append(codes, StatusCodeRange{Min: minCode, Max: maxCode})
+	}
+
+	return codes, nil
+}
+
+// Contains reports whether code is accepted. An empty set accepts 2XX codes.
+func (s StatusCodes) Contains(code int) bool {
+	if len(s) == 0 {
+		return code >= 200 && code < 300
+	}
+
+	for _, r := range s {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
 	}
 
-	// This should never be reached, but return the last known state
-	return false, lastStatus, nil
+	return false
 }
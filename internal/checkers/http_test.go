@@ -1,13 +1,29 @@
 package checkers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
 )
 
+// latencyPattern matches the latency reported after the HTTP status.
+var latencyPattern = regexp.MustCompile(` in [^ :]+`)
+
+// trimLatency removes the latency from status so it can be compared.
+func trimLatency(status string) string {
+	return latencyPattern.ReplaceAllString(status, "")
+}
+
+// testRetryPolicy keeps the default number of attempts but retries quickly.
+var testRetryPolicy = RetryPolicy{Attempts: 3, Timeout: time.Second, Delay: 50 * time.Millisecond}
+
 func TestSimpleHTTPChecker_CheckHealth(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -93,8 +109,9 @@ func TestSimpleHTTPChecker_CheckHealth(t *testing.T) {
 				// Use an unreachable URL to test connection error
 				checker = NewSimpleHTTPChecker("http://localhost:99999", false)
 			}
+			checker.Retry = testRetryPolicy
 
-			healthy, statusMsg, err := checker.CheckHealth()
+			healthy, statusMsg, err := checker.CheckHealth(context.Background())
 
 			// Check error expectation
 			if tt.wantErr {
@@ -115,7 +132,7 @@ func TestSimpleHTTPChecker_CheckHealth(t *testing.T) {
 			}
 
 			// Check status message for server-based tests
-			if tt.setupServer && statusMsg != tt.wantStatusMsg {
+			if tt.setupServer && trimLatency(statusMsg) != tt.wantStatusMsg {
 				t.Errorf("CheckHealth() statusMsg = %v, want %v", statusMsg, tt.wantStatusMsg)
 			}
 
@@ -172,7 +189,7 @@ func TestSimpleHTTPChecker_CheckHealth_Retries(t *testing.T) {
 		defer server.Close()
 
 		checker := NewSimpleHTTPChecker(server.URL, false)
-		healthy, statusMsg, err := checker.CheckHealth()
+		healthy, statusMsg, err := checker.CheckHealth(context.Background())
 
 		if err != nil {
 			t.Errorf("CheckHealth() unexpected error: %v", err)
@@ -180,7 +197,7 @@ func TestSimpleHTTPChecker_CheckHealth_Retries(t *testing.T) {
 		if !healthy {
 			t.Errorf("CheckHealth() healthy = false, want true")
 		}
-		if statusMsg != "200 OK" {
+		if trimLatency(statusMsg) != "200 OK" {
 			t.Errorf("CheckHealth() statusMsg = %v, want '200 OK'", statusMsg)
 		}
 		if attemptCount.Load() != 1 {
@@ -202,8 +219,9 @@ func TestSimpleHTTPChecker_CheckHealth_Retries(t *testing.T) {
 		defer server.Close()
 
 		checker := NewSimpleHTTPChecker(server.URL, false)
+		checker.Retry = testRetryPolicy
 		start := time.Now()
-		healthy, statusMsg, err := checker.CheckHealth()
+		healthy, statusMsg, err := checker.CheckHealth(context.Background())
 		elapsed := time.Since(start)
 
 		if err != nil {
@@ -212,20 +230,19 @@ func TestSimpleHTTPChecker_CheckHealth_Retries(t *testing.T) {
 		if !healthy {
 			t.Errorf("CheckHealth() healthy = false, want true")
 		}
-		if statusMsg != "200 OK" {
+		if trimLatency(statusMsg) != "200 OK" {
 			t.Errorf("CheckHealth() statusMsg = %v, want '200 OK'", statusMsg)
 		}
 		if attemptCount.Load() != 2 {
 			t.Errorf("Expected 2 attempts, got %d", attemptCount.Load())
 		}
-		// Should have waited at least 10 seconds for one retry
-		if elapsed < 10*time.Second {
-			t.Errorf("Expected at least 10s elapsed, got %v", elapsed)
+		// Should have waited at least one retry delay
+		if elapsed < testRetryPolicy.Delay {
+			t.Errorf("Expected at least %v elapsed, got %v", testRetryPolicy.Delay, elapsed)
 		}
 	})
 
 	t.Run("succeeds on third attempt after two failures", func(t *testing.T) {
-		t.SkipNow() // Skipping to avoid long test times due to retries until retry logic is configurable
 		var attemptCount atomic.Int32
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -239,8 +256,9 @@ func TestSimpleHTTPChecker_CheckHealth_Retries(t *testing.T) {
 		defer server.Close()
 
 		checker := NewSimpleHTTPChecker(server.URL, false)
+		checker.Retry = testRetryPolicy
 		start := time.Now()
-		healthy, statusMsg, err := checker.CheckHealth()
+		healthy, statusMsg, err := checker.CheckHealth(context.Background())
 		elapsed := time.Since(start)
 
 		if err != nil {
@@ -249,20 +267,19 @@ func TestSimpleHTTPChecker_CheckHealth_Retries(t *testing.T) {
 		if !healthy {
 			t.Errorf("CheckHealth() healthy = false, want true")
 		}
-		if statusMsg != "200 OK" {
+		if trimLatency(statusMsg) != "200 OK" {
 			t.Errorf("CheckHealth() statusMsg = %v, want '200 OK'", statusMsg)
 		}
 		if attemptCount.Load() != 3 {
 			t.Errorf("Expected 3 attempts, got %d", attemptCount.Load())
 		}
-		// Should have waited at least 20 seconds for two retries
-		if elapsed < 20*time.Second {
-			t.Errorf("Expected at least 20s elapsed, got %v", elapsed)
+		// Should have waited for two retries
+		if elapsed < 2*testRetryPolicy.Delay {
+			t.Errorf("Expected at least %v elapsed, got %v", 2*testRetryPolicy.Delay, elapsed)
 		}
 	})
 
 	t.Run("fails after 3 attempts with unhealthy status", func(t *testing.T) {
-		t.SkipNow() // Skipping to avoid long test times due to retries until retry logic is configurable
 		var attemptCount atomic.Int32
 
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -272,8 +289,9 @@ func TestSimpleHTTPChecker_CheckHealth_Retries(t *testing.T) {
 		defer server.Close()
 
 		checker := NewSimpleHTTPChecker(server.URL, false)
+		checker.Retry = testRetryPolicy
 		start := time.Now()
-		healthy, statusMsg, err := checker.CheckHealth()
+		healthy, statusMsg, err := checker.CheckHealth(context.Background())
 		elapsed := time.Since(start)
 
 		if err != nil {
@@ -282,24 +300,24 @@ func TestSimpleHTTPChecker_CheckHealth_Retries(t *testing.T) {
 		if healthy {
 			t.Errorf("CheckHealth() healthy = true, want false")
 		}
-		if statusMsg != "503 Service Unavailable" {
+		if trimLatency(statusMsg) != "503 Service Unavailable" {
 			t.Errorf("CheckHealth() statusMsg = %v, want '503 Service Unavailable'", statusMsg)
 		}
 		if attemptCount.Load() != 3 {
 			t.Errorf("Expected 3 attempts, got %d", attemptCount.Load())
 		}
-		// Should have waited at least 20 seconds for two retries
-		if elapsed < 20*time.Second {
-			t.Errorf("Expected at least 20s elapsed, got %v", elapsed)
+		// Should have waited for two retries
+		if elapsed < 2*testRetryPolicy.Delay {
+			t.Errorf("Expected at least %v elapsed, got %v", 2*testRetryPolicy.Delay, elapsed)
 		}
 	})
 
 	t.Run("fails after 3 connection error attempts", func(t *testing.T) {
-		t.SkipNow() // Skipping to avoid long test times due to retries until retry logic is configurable
 		// Use an unreachable address
 		checker := NewSimpleHTTPChecker("http://localhost:99999", false)
+		checker.Retry = testRetryPolicy
 		start := time.Now()
-		healthy, statusMsg, err := checker.CheckHealth()
+		healthy, statusMsg, err := checker.CheckHealth(context.Background())
 		elapsed := time.Since(start)
 
 		if err != nil {
@@ -311,10 +329,319 @@ func TestSimpleHTTPChecker_CheckHealth_Retries(t *testing.T) {
 		if statusMsg == "" {
 			t.Errorf("CheckHealth() statusMsg should contain error message")
 		}
-		// Should have waited at least 20 seconds for two retries
-		// (3 attempts total, 2 retries with 10s delay each)
-		if elapsed < 20*time.Second {
-			t.Errorf("Expected at least 20s elapsed, got %v", elapsed)
+		// Should have waited for two retries
+		// (3 attempts total, 2 retries with the retry delay each)
+		if elapsed < 2*testRetryPolicy.Delay {
+			t.Errorf("Expected at least %v elapsed, got %v", 2*testRetryPolicy.Delay, elapsed)
+		}
+	})
+}
+
+func TestSimpleHTTPChecker_CheckHealth_BodyAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"degraded","checks":{"db":"up"}}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	t.Run("all assertions pass", func(t *testing.T) {
+		checker := NewSimpleHTTPChecker(server.URL, false)
+		checker.BodyAssertions = []BodyAssertion{
+			ContainsAssertion("checks"),
+			&JSONPathAssertion{Path: "checks.db", Op: "==", Value: "up"},
+		}
+
+		healthy, statusMsg, err := checker.CheckHealth(context.Background())
+		if err != nil {
+			t.Fatalf("CheckHealth() unexpected error: %v", err)
+		}
+		if !healthy {
+			t.Errorf("CheckHealth() healthy = false, want true")
+		}
+		if trimLatency(statusMsg) != "200 OK" {
+			t.Errorf("CheckHealth() statusMsg = %v, want '200 OK'", statusMsg)
 		}
 	})
+
+	t.Run("failed assertion reported in status", func(t *testing.T) {
+		checker := NewSimpleHTTPChecker(server.URL, false)
+		checker.Retry = testRetryPolicy
+		checker.BodyAssertions = []BodyAssertion{
+			ContainsAssertion("checks"),
+			&JSONPathAssertion{Path: "status", Op: "==", Value: `"ok"`},
+		}
+
+		healthy, statusMsg, err := checker.CheckHealth(context.Background())
+		if err != nil {
+			t.Fatalf("CheckHealth() unexpected error: %v", err)
+		}
+		if healthy {
+			t.Errorf("CheckHealth() healthy = true, want false")
+		}
+
+		want := `200 OK: status == "ok": got "degraded"`
+		if trimLatency(statusMsg) != want {
+			t.Errorf("CheckHealth() statusMsg = %v, want %v", statusMsg, want)
+		}
+	})
+}
+
+func TestSimpleHTTPChecker_CheckHealth_RequestOptions(t *testing.T) {
+	var gotMethod, gotHost, gotHeader, gotServerName string
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotHost = r.Host
+		gotHeader = r.Header.Get("X-Health-Token")
+		gotServerName = r.TLS.ServerName
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	checker := NewSimpleHTTPChecker(server.URL, true)
+	checker.Method = http.MethodHead
+	checker.Headers = http.Header{"X-Health-Token": {"secret"}}
+	checker.Host = "api.example.com"
+	checker.ServerName = "sni.example.com"
+
+	healthy, statusMsg, err := checker.CheckHealth(context.Background())
+	if err != nil {
+		t.Fatalf("CheckHealth() unexpected error: %v", err)
+	}
+	if !healthy {
+		t.Errorf("CheckHealth() healthy = false, want true")
+	}
+	if trimLatency(statusMsg) != "204 No Content" {
+		t.Errorf("CheckHealth() statusMsg = %v, want '204 No Content'", statusMsg)
+	}
+
+	if gotMethod != http.MethodHead {
+		t.Errorf("Expected method %s, got %s", http.MethodHead, gotMethod)
+	}
+	if gotHost != "api.example.com" {
+		t.Errorf("Expected Host header 'api.example.com', got %s", gotHost)
+	}
+	if gotHeader != "secret" {
+		t.Errorf("Expected X-Health-Token header 'secret', got %s", gotHeader)
+	}
+	if gotServerName != "sni.example.com" {
+		t.Errorf("Expected TLS server name 'sni.example.com', got %s", gotServerName)
+	}
+}
+
+func TestSimpleHTTPChecker_CheckHealth_StatusCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	codes, err := ParseStatusCodes("200-299,401")
+	if err != nil {
+		t.Fatalf("ParseStatusCodes() failed: %v", err)
+	}
+
+	checker := NewSimpleHTTPChecker(server.URL, false)
+	checker.StatusCodes = codes
+
+	healthy, statusMsg, err := checker.CheckHealth(context.Background())
+	if err != nil {
+		t.Fatalf("CheckHealth() unexpected error: %v", err)
+	}
+	if !healthy {
+		t.Errorf("CheckHealth() healthy = false, want true")
+	}
+	if trimLatency(statusMsg) != "401 Unauthorized" {
+		t.Errorf("CheckHealth() statusMsg = %v, want '401 Unauthorized'", statusMsg)
+	}
+}
+
+func TestParseStatusCodes(t *testing.T) {
+	tests := []struct {
+		input    string
+		accepted []int
+		rejected []int
+		wantErr  bool
+	}{
+		{input: "", accepted: []int{200, 204, 299}, rejected: []int{199, 301, 404}},
+		{input: "200", accepted: []int{200}, rejected: []int{201, 204}},
+		{input: "200-204, 301,302", accepted: []int{200, 204, 301, 302}, rejected: []int{205, 300, 303}},
+		{input: "2xx", wantErr: true},
+		{input: "300-200", wantErr: true},
+		{input: "99", wantErr: true},
+		{input: "200-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			codes, err := ParseStatusCodes(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseStatusCodes() expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseStatusCodes() unexpected error: %v", err)
+			}
+
+			for _, code := range tt.accepted {
+				if !codes.Contains(code) {
+					t.Errorf("Contains(%d) = false, want true", code)
+				}
+			}
+
+			for _, code := range tt.rejected {
+				if codes.Contains(code) {
+					t.Errorf("Contains(%d) = true, want false", code)
+				}
+			}
+		})
+	}
+}
+
+func TestSimpleHTTPChecker_CheckHealth_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// Cancel while the checker waits before retrying
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	checker := NewSimpleHTTPChecker(server.URL, false)
+	start := time.Now()
+	_, _, err := checker.CheckHealth(ctx)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CheckHealth() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed > time.Second {
+		t.Errorf("Expected CheckHealth() to return promptly, took %v", elapsed)
+	}
+}
+
+func TestSimpleHTTPChecker_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(50 * time.Millisecond)
+		case "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		case "/hang":
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"status": "ok"}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+
+	tests := []struct {
+		name         string
+		url          string
+		configure    func(c *SimpleHTTPChecker)
+		wantState    gslb.State
+		wantClass    gslb.ErrorClass
+		wantAttempts int
+		wantCode     string
+	}{
+		{
+			name:         "healthy",
+			url:          server.URL,
+			wantState:    gslb.StateHealthy,
+			wantAttempts: 1,
+			wantCode:     "200",
+		},
+		{
+			name:         "status",
+			url:          server.URL + "/down",
+			wantState:    gslb.StateUnhealthy,
+			wantClass:    gslb.ErrorStatus,
+			wantAttempts: 3,
+			wantCode:     "503",
+		},
+		{
+			name: "body",
+			url:  server.URL,
+			configure: func(c *SimpleHTTPChecker) {
+				c.BodyAssertions = []BodyAssertion{ContainsAssertion("degraded")}
+			},
+			wantState:    gslb.StateUnhealthy,
+			wantClass:    gslb.ErrorResponse,
+			wantAttempts: 3,
+			wantCode:     "200",
+		},
+		{
+			name: "latency degraded",
+			url:  server.URL + "/slow",
+			configure: func(c *SimpleHTTPChecker) {
+				c.Latency = &LatencyThreshold{Max: 10 * time.Millisecond, Degrade: true}
+			},
+			wantState:    gslb.StateDegraded,
+			wantClass:    gslb.ErrorLatency,
+			wantAttempts: 1,
+			wantCode:     "200",
+		},
+		{
+			name: "timeout",
+			url:  server.URL + "/hang",
+			configure: func(c *SimpleHTTPChecker) {
+				c.Retry = RetryPolicy{Attempts: 1, Timeout: 20 * time.Millisecond}
+			},
+			wantState:    gslb.StateUnhealthy,
+			wantClass:    gslb.ErrorTimeout,
+			wantAttempts: 1,
+		},
+		{
+			name:         "tls",
+			url:          tlsServer.URL,
+			wantState:    gslb.StateUnhealthy,
+			wantClass:    gslb.ErrorTLS,
+			wantAttempts: 3,
+		},
+		{
+			name:         "connection",
+			url:          "http://localhost:99999",
+			wantState:    gslb.StateUnhealthy,
+			wantClass:    gslb.ErrorConnection,
+			wantAttempts: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewSimpleHTTPChecker(tt.url, false)
+			checker.Retry = RetryPolicy{Attempts: 3, Timeout: time.Second, Delay: time.Millisecond}
+			if tt.configure != nil {
+				tt.configure(checker)
+			}
+
+			res, err := checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState {
+				t.Errorf("Check() state = %v, want %v (status %q)", res.State, tt.wantState, res.Status)
+			}
+			if res.Class != tt.wantClass {
+				t.Errorf("Check() class = %q, want %q (status %q)", res.Class, tt.wantClass, res.Status)
+			}
+			if res.Attempts != tt.wantAttempts {
+				t.Errorf("Check() attempts = %d, want %d", res.Attempts, tt.wantAttempts)
+			}
+			if res.Details["status_code"] != tt.wantCode {
+				t.Errorf("Check() status_code = %q, want %q", res.Details["status_code"], tt.wantCode)
+			}
+			if res.Latency <= 0 {
+				t.Errorf("Check() latency = %v, want > 0", res.Latency)
+			}
+		})
+	}
 }
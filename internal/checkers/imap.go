@@ -0,0 +1,160 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// IMAPChecker reads the greeting of an IMAP server and sends CAPABILITY,
+// optionally upgrading the connection with STARTTLS first.
+type IMAPChecker struct {
+	Address string
+
+	TLSMode       TLSMode
+	SkipTLSVerify bool
+	ServerName    string
+	TLSFiles      *TLSFiles
+	MinTLSVersion uint16
+
+	Retry RetryPolicy
+}
+
+func NewIMAPChecker(address string) *IMAPChecker {
+	return &IMAPChecker{
+		Address: address,
+		Retry:   SingleAttemptPolicy(5 * time.Second),
+	}
+}
+
+// Check implements gslb.HealthChecker.
+func (c *IMAPChecker) Check(ctx context.Context) (gslb.Result, error) {
+	// Validate address before attempting to connect
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return gslb.Result{}, err
+	}
+
+	cfg, err := protocolTLSConfig(c.Address, c.SkipTLSVerify, c.ServerName, c.MinTLSVersion, c.TLSFiles)
+	if err != nil {
+		return gslb.Result{}, err
+	}
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		return c.check(ctx, cfg)
+	})
+}
+
+func (c *IMAPChecker) check(ctx context.Context, cfg *tls.Config) gslb.Result {
+	start := time.Now()
+
+	conn, err := dialProtocol(ctx, c.Address, c.TLSMode, cfg)
+	if err != nil {
+		return failure(errorClass(err), err.Error(), time.Since(start))
+	}
+	defer func() { conn.Close() }() //nolint:errcheck
+
+	r, w := newTextConn(conn)
+
+	greeting, err := r.ReadLine()
+	if err != nil {
+		return failure(errorClass(err), fmt.Sprintf("greeting: %s", err), time.Since(start))
+	}
+
+	if !strings.HasPrefix(greeting, "* OK") && !strings.HasPrefix(greeting, "* PREAUTH") {
+		return failure(gslb.ErrorResponse, fmt.Sprintf("unexpected greeting %q", greeting), time.Since(start))
+	}
+
+	caps, err := imapCapability(r, w, "a1")
+	if err != nil {
+		return failure(protocolErrorClass(err), fmt.Sprintf("CAPABILITY: %s", err), time.Since(start))
+	}
+
+	status := "CAPABILITY succeeded"
+
+	if c.TLSMode == TLSStartTLS {
+		if !slices.Contains(caps, "STARTTLS") {
+			return failure(gslb.ErrorTLS, "STARTTLS not offered", time.Since(start))
+		}
+
+		if _, err := imapCommand(r, w, "a2", "STARTTLS"); err != nil {
+			return failure(protocolErrorClass(err), fmt.Sprintf("STARTTLS: %s", err), time.Since(start))
+		}
+
+		tlsConn, err := startTLS(ctx, conn, cfg)
+		if err != nil {
+			return failure(errorClass(err), err.Error(), time.Since(start))
+		}
+
+		conn = tlsConn
+		r, w = newTextConn(conn)
+
+		if caps, err = imapCapability(r, w, "a3"); err != nil {
+			return failure(protocolErrorClass(err), fmt.Sprintf("CAPABILITY after STARTTLS: %s", err), time.Since(start))
+		}
+
+		status += " after STARTTLS"
+	}
+
+	latency := time.Since(start)
+
+	// Say goodbye politely, the result does not depend on it
+	w.PrintfLine("a9 LOGOUT") //nolint:errcheck
+
+	return gslb.Result{
+		State:   gslb.StateHealthy,
+		Status:  fmt.Sprintf("%s in %s: %s", status, formatLatency(latency), greeting),
+		Latency: latency,
+		Details: map[string]string{"capabilities": strings.Join(caps, " ")},
+	}
+}
+
+// imapCommand sends a tagged command and returns the untagged responses. The
+// tagged response must be OK.
+func imapCommand(r *textproto.Reader, w *textproto.Writer, tag, cmd string) ([]string, error) {
+	if err := w.PrintfLine("%s %s", tag, cmd); err != nil {
+		return nil, err
+	}
+
+	var untagged []string
+	for {
+		line, err := r.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+
+		rest, ok := strings.CutPrefix(line, tag+" ")
+		if !ok {
+			untagged = append(untagged, line)
+			continue
+		}
+
+		if !strings.HasPrefix(rest, "OK") {
+			return nil, textproto.ProtocolError(rest)
+		}
+
+		return untagged, nil
+	}
+}
+
+// imapCapability sends CAPABILITY and returns the capabilities listed.
+func imapCapability(r *textproto.Reader, w *textproto.Writer, tag string) ([]string, error) {
+	untagged, err := imapCommand(r, w, tag, "CAPABILITY")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range untagged {
+		if caps, ok := strings.CutPrefix(line, "* CAPABILITY "); ok {
+			return strings.Fields(caps), nil
+		}
+	}
+
+	return nil, textproto.ProtocolError("no CAPABILITY response")
+}
@@ -0,0 +1,118 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// serveIMAP answers like an IMAP server offering STARTTLS if cert is set.
+func serveIMAP(cert *tls.Certificate, greeting string) func(net.Conn) {
+	return func(conn net.Conn) {
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("%s", greeting) //nolint:errcheck
+
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+
+			tag, cmd, _ := strings.Cut(line, " ")
+
+			switch cmd {
+			case "CAPABILITY":
+				caps := "IMAP4rev1 AUTH=PLAIN"
+				if cert != nil {
+					caps += " STARTTLS"
+				}
+
+				tp.PrintfLine("* CAPABILITY %s\r\n%s OK CAPABILITY completed", caps, tag) //nolint:errcheck
+			case "STARTTLS":
+				tp.PrintfLine("%s OK Begin TLS negotiation now", tag) //nolint:errcheck
+
+				tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{*cert}})
+				if err := tlsConn.Handshake(); err != nil {
+					return
+				}
+
+				conn = tlsConn
+				tp = textproto.NewConn(tlsConn)
+				cert = nil
+			case "LOGOUT":
+				tp.PrintfLine("* BYE\r\n%s OK LOGOUT completed", tag) //nolint:errcheck
+				return
+			default:
+				tp.PrintfLine("%s BAD unknown command", tag) //nolint:errcheck
+			}
+		}
+	}
+}
+
+func TestIMAPChecker_Check(t *testing.T) {
+	cert := newTestCertificate(t, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name       string
+		handler    func(net.Conn)
+		mode       TLSMode
+		wantState  gslb.State
+		wantClass  gslb.ErrorClass
+		wantStatus string
+	}{
+		{
+			name:       "plaintext",
+			handler:    serveIMAP(nil, "* OK IMAP4rev1 ready"),
+			wantState:  gslb.StateHealthy,
+			wantStatus: "CAPABILITY succeeded in",
+		},
+		{
+			name:       "starttls",
+			handler:    serveIMAP(&cert, "* OK IMAP4rev1 ready"),
+			mode:       TLSStartTLS,
+			wantState:  gslb.StateHealthy,
+			wantStatus: "CAPABILITY succeeded after STARTTLS in",
+		},
+		{
+			name:       "starttls not offered",
+			handler:    serveIMAP(nil, "* OK IMAP4rev1 ready"),
+			mode:       TLSStartTLS,
+			wantState:  gslb.StateUnhealthy,
+			wantClass:  gslb.ErrorTLS,
+			wantStatus: "STARTTLS not offered",
+		},
+		{
+			name:       "rejected",
+			handler:    serveIMAP(nil, "* BYE too many connections"),
+			wantState:  gslb.StateUnhealthy,
+			wantClass:  gslb.ErrorResponse,
+			wantStatus: `unexpected greeting "* BYE too many connections"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewIMAPChecker(startTCPServer(t, tt.handler))
+			c.TLSMode = tt.mode
+			c.SkipTLSVerify = true
+
+			res, err := c.Check(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState || res.Class != tt.wantClass {
+				t.Errorf("expected %s (%q), got %s (%q): %s", tt.wantState, tt.wantClass, res.State, res.Class, res.Status)
+			}
+			if !strings.HasPrefix(res.Status, tt.wantStatus) {
+				t.Errorf("expected status starting with %q, got %q", tt.wantStatus, res.Status)
+			}
+		})
+	}
+}
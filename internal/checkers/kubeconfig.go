@@ -0,0 +1,235 @@
+package checkers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Locations of the service account credentials mounted into pods.
+const (
+	inClusterTokenFile     = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	inClusterCAFile        = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	inClusterNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// KubeConfig holds what is needed to talk to a Kubernetes API server.
+type KubeConfig struct {
+	// Server is the API server URL, e.g. https://10.0.0.1:6443.
+	Server string
+
+	// Token is sent as bearer token. TokenFile takes precedence and is read
+	// on every request, as service account tokens are rotated.
+	Token     string
+	TokenFile string
+
+	// Namespace is the default namespace of the context.
+	Namespace string
+
+	TLS *tls.Config
+}
+
+// InClusterKubeConfig returns the configuration of the service account of
+// the pod the switcher runs in.
+func InClusterKubeConfig() (*KubeConfig, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running in a cluster, KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	}
+
+	cfg := &KubeConfig{
+		Server:    "https://" + net.JoinHostPort(host, port),
+		TokenFile: inClusterTokenFile,
+		Namespace: "default",
+		TLS:       &tls.Config{},
+	}
+
+	ca, err := os.ReadFile(inClusterCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading service account CA: %w", err)
+	}
+
+	if cfg.TLS.RootCAs, err = certPool(ca); err != nil {
+		return nil, err
+	}
+
+	if ns, err := os.ReadFile(inClusterNamespaceFile); err == nil {
+		cfg.Namespace = strings.TrimSpace(string(ns))
+	}
+
+	return cfg, nil
+}
+
+// kubeconfigFile is the subset of a kubeconfig file that is supported.
+type kubeconfigFile struct {
+	CurrentContext string              `json:"current-context"`
+	Clusters       []kubeconfigCluster `json:"clusters"`
+	Users          []kubeconfigUser    `json:"users"`
+	Contexts       []kubeconfigContext `json:"contexts"`
+}
+
+type kubeconfigCluster struct {
+	Name    string `json:"name"`
+	Cluster struct {
+		Server                   string `json:"server"`
+		TLSServerName            string `json:"tls-server-name"`
+		InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify"`
+		CertificateAuthority     string `json:"certificate-authority"`
+		CertificateAuthorityData []byte `json:"certificate-authority-data"`
+	} `json:"cluster"`
+}
+
+type kubeconfigUser struct {
+	Name string `json:"name"`
+	User struct {
+		Token                 string `json:"token"`
+		TokenFile             string `json:"tokenFile"`
+		ClientCertificate     string `json:"client-certificate"`
+		ClientCertificateData []byte `json:"client-certificate-data"`
+		ClientKey             string `json:"client-key"`
+		ClientKeyData         []byte `json:"client-key-data"`
+		Exec                  any    `json:"exec"`
+		AuthProvider          any    `json:"auth-provider"`
+	} `json:"user"`
+}
+
+type kubeconfigContext struct {
+	Name    string `json:"name"`
+	Context struct {
+		Cluster   string `json:"cluster"`
+		User      string `json:"user"`
+		Namespace string `json:"namespace"`
+	} `json:"context"`
+}
+
+// LoadKubeConfig reads a kubeconfig file, using the named context or the
+// current context if empty. Token and client certificate authentication are
+// supported, exec and auth provider plugins are not.
+func LoadKubeConfig(path, context string) (*KubeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f kubeconfigFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing kubeconfig %s: %w", path, err)
+	}
+
+	if context == "" {
+		context = f.CurrentContext
+	}
+
+	// Relative file references are relative to the kubeconfig
+	dir := filepath.Dir(path)
+	resolve := func(name string) string {
+		if name == "" || filepath.IsAbs(name) {
+			return name
+		}
+
+		return filepath.Join(dir, name)
+	}
+
+	i := slices.IndexFunc(f.Contexts, func(c kubeconfigContext) bool { return c.Name == context })
+	if i < 0 {
+		return nil, fmt.Errorf("context %q not found in kubeconfig %s", context, path)
+	}
+	kctx := f.Contexts[i].Context
+
+	ci := slices.IndexFunc(f.Clusters, func(c kubeconfigCluster) bool { return c.Name == kctx.Cluster })
+	if ci < 0 {
+		return nil, fmt.Errorf("cluster %q not found in kubeconfig %s", kctx.Cluster, path)
+	}
+	cluster := f.Clusters[ci].Cluster
+
+	cfg := &KubeConfig{
+		Server:    cluster.Server,
+		Namespace: kctx.Namespace,
+		TLS: &tls.Config{
+			ServerName:         cluster.TLSServerName,
+			InsecureSkipVerify: cluster.InsecureSkipTLSVerify,
+		},
+	}
+
+	if cfg.Namespace == "" {
+		cfg.Namespace = "default"
+	}
+
+	ca := cluster.CertificateAuthorityData
+	if len(ca) == 0 && cluster.CertificateAuthority != "" {
+		if ca, err = os.ReadFile(resolve(cluster.CertificateAuthority)); err != nil {
+			return nil, fmt.Errorf("reading certificate authority: %w", err)
+		}
+	}
+
+	if len(ca) > 0 {
+		if cfg.TLS.RootCAs, err = certPool(ca); err != nil {
+			return nil, err
+		}
+	}
+
+	if kctx.User == "" {
+		return cfg, nil
+	}
+
+	ui := slices.IndexFunc(f.Users, func(u kubeconfigUser) bool { return u.Name == kctx.User })
+	if ui < 0 {
+		return nil, fmt.Errorf("user %q not found in kubeconfig %s", kctx.User, path)
+	}
+	user := f.Users[ui].User
+
+	if user.Exec != nil || user.AuthProvider != nil {
+		return nil, fmt.Errorf("user %q uses an exec or auth provider plugin, which is not supported", kctx.User)
+	}
+
+	cfg.Token = user.Token
+	cfg.TokenFile = resolve(user.TokenFile)
+
+	cert, key := user.ClientCertificateData, user.ClientKeyData
+	if len(cert) == 0 && user.ClientCertificate != "" {
+		if cert, err = os.ReadFile(resolve(user.ClientCertificate)); err != nil {
+			return nil, fmt.Errorf("reading client certificate: %w", err)
+		}
+	}
+	if len(key) == 0 && user.ClientKey != "" {
+		if key, err = os.ReadFile(resolve(user.ClientKey)); err != nil {
+			return nil, fmt.Errorf("reading client key: %w", err)
+		}
+	}
+
+	if len(cert) > 0 || len(key) > 0 {
+		crt, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+
+		cfg.TLS.Certificates = []tls.Certificate{crt}
+	}
+
+	return cfg, nil
+}
+
+// token returns the bearer token to send, if any.
+func (c *KubeConfig) token() (string, error) {
+	if c.TokenFile != "" {
+		return readSecretFile(c.TokenFile)
+	}
+
+	return c.Token, nil
+}
+
+func certPool(pem []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in certificate authority")
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,291 @@
+package checkers
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+type KubernetesKind string
+
+const (
+	// KubernetesEndpoints counts the ready endpoints in the EndpointSlices
+	// of a Service.
+	KubernetesEndpoints KubernetesKind = "endpoints"
+	// KubernetesNodes counts the Ready nodes matching a label selector.
+	KubernetesNodes KubernetesKind = "nodes"
+)
+
+// maxKubernetesListSize limits the size of list responses, which grow with
+// the cluster.
+const maxKubernetesListSize = 16 << 20
+
+// KubernetesChecker asks the Kubernetes API whether the workload behind the
+// GSLB host is serving, rather than whether the API server itself is up.
+//
+// The target is unhealthy if fewer than MinReady endpoints or nodes are
+// ready and degraded if enough are ready but others are not.
+type KubernetesChecker struct {
+	Config *KubeConfig
+	Kind   KubernetesKind
+
+	// Namespace and Service select the EndpointSlices for the endpoints
+	// kind. The namespace of the config is used if Namespace is empty.
+	Namespace string
+	Service   string
+
+	// Selector is the label selector of the nodes kind, all nodes are
+	// counted if empty.
+	Selector string
+
+	// MinReady is the number of endpoints or nodes that must be ready, 1 if
+	// zero.
+	MinReady int
+
+	Retry RetryPolicy
+}
+
+func NewKubernetesChecker(cfg *KubeConfig, kind KubernetesKind) *KubernetesChecker {
+	return &KubernetesChecker{
+		Config:   cfg,
+		Kind:     kind,
+		MinReady: 1,
+		Retry:    SingleAttemptPolicy(5 * time.Second),
+	}
+}
+
+// Validate reports configuration errors of the checker.
+func (c *KubernetesChecker) Validate() error {
+	switch {
+	case c.Config == nil || c.Config.Server == "":
+		return fmt.Errorf("API server must be set")
+	case c.Kind == KubernetesEndpoints && c.Service == "":
+		return fmt.Errorf("service must be set")
+	case c.Kind != KubernetesEndpoints && c.Kind != KubernetesNodes:
+		return fmt.Errorf("unsupported kind %q", c.Kind)
+	}
+
+	return nil
+}
+
+// Check implements gslb.HealthChecker.
+func (c *KubernetesChecker) Check(ctx context.Context) (gslb.Result, error) {
+	if err := c.Validate(); err != nil {
+		return gslb.Result{}, err
+	}
+
+	token, err := c.Config.token()
+	if err != nil {
+		return gslb.Result{}, err
+	}
+
+	u, err := url.Parse(c.Config.Server)
+	if err != nil {
+		return gslb.Result{}, err
+	}
+
+	var subject string
+	if c.Kind == KubernetesEndpoints {
+		ns := cmp.Or(c.Namespace, c.Config.Namespace, "default")
+		subject = fmt.Sprintf("endpoints of service %s/%s", ns, c.Service)
+		u = u.JoinPath("apis/discovery.k8s.io/v1/namespaces", ns, "endpointslices")
+		u.RawQuery = url.Values{"labelSelector": {"kubernetes.io/service-name=" + c.Service}}.Encode()
+	} else {
+		subject = "nodes"
+		if c.Selector != "" {
+			subject = fmt.Sprintf("nodes matching %s", c.Selector)
+		}
+		u = u.JoinPath("api/v1/nodes")
+		u.RawQuery = url.Values{"labelSelector": {c.Selector}}.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return gslb.Result{}, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	transport := &http.Transport{TLSClientConfig: c.Config.TLS}
+	defer transport.CloseIdleConnections()
+
+	client := &http.Client{Transport: transport}
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		start := time.Now()
+
+		resp, err := client.Do(req.Clone(ctx))
+		if err != nil {
+			return failure(errorClass(err), err.Error(), time.Since(start))
+		}
+		defer resp.Body.Close() //nolint:errcheck
+
+		if resp.StatusCode != http.StatusOK {
+			return failure(gslb.ErrorStatus, fmt.Sprintf("listing %s: %s", subject, resp.Status), time.Since(start))
+		}
+
+		body := io.LimitReader(resp.Body, maxKubernetesListSize)
+
+		var ready, notReady []string
+		if c.Kind == KubernetesEndpoints {
+			ready, notReady, err = endpointReadiness(body)
+		} else {
+			ready, notReady, err = nodeReadiness(body)
+		}
+
+		latency := time.Since(start)
+
+		if err != nil {
+			return failure(gslb.ErrorResponse, fmt.Sprintf("decoding response: %s", err), latency)
+		}
+
+		res := c.evaluate(subject, ready, notReady)
+		res.Latency = latency
+
+		return res
+	})
+}
+
+// evaluate compares the number of ready endpoints or nodes with MinReady.
+func (c *KubernetesChecker) evaluate(subject string, ready, notReady []string) gslb.Result {
+	minReady := max(c.MinReady, 1)
+	total := len(ready) + len(notReady)
+
+	res := gslb.Result{
+		State:  gslb.StateHealthy,
+		Status: fmt.Sprintf("%d of %d %s ready", len(ready), total, subject),
+		Details: map[string]string{
+			"ready":     strconv.Itoa(len(ready)),
+			"total":     strconv.Itoa(total),
+			"min_ready": strconv.Itoa(minReady),
+		},
+	}
+
+	switch {
+	case len(ready) < minReady:
+		res.State = gslb.StateUnhealthy
+		res.Class = gslb.ErrorResponse
+		res.Status += fmt.Sprintf(", %d required", minReady)
+	case len(notReady) > 0:
+		res.State = gslb.StateDegraded
+	}
+
+	if len(notReady) > 0 {
+		res.Status += ", not ready: " + joinLimited(notReady)
+	}
+
+	return res
+}
+
+type endpointSliceList struct {
+	Items []struct {
+		Endpoints []struct {
+			Addresses  []string `json:"addresses"`
+			Conditions struct {
+				Ready *bool `json:"ready"`
+			} `json:"conditions"`
+			TargetRef *struct {
+				Kind      string `json:"kind"`
+				Namespace string `json:"namespace"`
+				Name      string `json:"name"`
+			} `json:"targetRef"`
+		} `json:"endpoints"`
+	} `json:"items"`
+}
+
+// endpointReadiness returns the names of the ready and not ready endpoints.
+// Endpoints are identified by their target, so that pods listed in both the
+// IPv4 and the IPv6 slice of a dual-stack Service count once.
+func endpointReadiness(r io.Reader) ([]string, []string, error) {
+	var list endpointSliceList
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, nil, err
+	}
+
+	seen := map[string]bool{}
+	var ready, notReady []string
+
+	for _, slice := range list.Items {
+		for _, ep := range slice.Endpoints {
+			var name string
+			switch {
+			case ep.TargetRef != nil:
+				name = ep.TargetRef.Name
+			case len(ep.Addresses) > 0:
+				name = ep.Addresses[0]
+			default:
+				continue
+			}
+
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			// A missing ready condition means ready
+			if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+				ready = append(ready, name)
+			} else {
+				notReady = append(notReady, name)
+			}
+		}
+	}
+
+	slices.Sort(notReady)
+
+	return ready, notReady, nil
+}
+
+type nodeList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			Conditions []nodeCondition `json:"conditions"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+type nodeCondition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// nodeReadiness returns the names of the Ready and not Ready nodes.
+func nodeReadiness(r io.Reader) ([]string, []string, error) {
+	var list nodeList
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, nil, err
+	}
+
+	var ready, notReady []string
+
+	for _, node := range list.Items {
+		isReady := slices.ContainsFunc(node.Status.Conditions, func(c nodeCondition) bool {
+			return c.Type == "Ready" && c.Status == "True"
+		})
+
+		if isReady {
+			ready = append(ready, node.Metadata.Name)
+		} else {
+			notReady = append(notReady, node.Metadata.Name)
+		}
+	}
+
+	slices.Sort(notReady)
+
+	return ready, notReady, nil
+}
@@ -0,0 +1,223 @@
+package checkers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+const (
+	kubeEndpointSlices = `{"items":[
+		{"addressType":"IPv4","endpoints":[
+			{"addresses":["10.1.0.1"],"conditions":{"ready":true},"targetRef":{"kind":"Pod","name":"api-a"}},
+			{"addresses":["10.1.0.2"],"conditions":{"ready":false},"targetRef":{"kind":"Pod","name":"api-b"}},
+			{"addresses":["10.1.0.3"],"conditions":{},"targetRef":{"kind":"Pod","name":"api-c"}}]},
+		{"addressType":"IPv6","endpoints":[
+			{"addresses":["fd00::1"],"conditions":{"ready":true},"targetRef":{"kind":"Pod","name":"api-a"}}]}]}`
+
+	kubeNodes = `{"items":[
+		{"metadata":{"name":"worker-1"},"status":{"conditions":[{"type":"MemoryPressure","status":"False"},{"type":"Ready","status":"True"}]}},
+		{"metadata":{"name":"worker-2"},"status":{"conditions":[{"type":"Ready","status":"Unknown"}]}}]}`
+)
+
+// startKubernetesServer serves EndpointSlices of the api Service in the
+// prod namespace and nodes labeled role=worker like the Kubernetes API.
+func startKubernetesServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		selector := r.URL.Query().Get("labelSelector")
+
+		switch {
+		case r.URL.Path == "/apis/discovery.k8s.io/v1/namespaces/prod/endpointslices" &&
+			selector == "kubernetes.io/service-name=api":
+			w.Write([]byte(kubeEndpointSlices)) //nolint:errcheck
+		case r.URL.Path == "/apis/discovery.k8s.io/v1/namespaces/prod/endpointslices":
+			w.Write([]byte(`{"items":[]}`)) //nolint:errcheck
+		case r.URL.Path == "/api/v1/nodes" && selector == "role=worker":
+			w.Write([]byte(kubeNodes)) //nolint:errcheck
+		case r.URL.Path == "/api/v1/nodes":
+			w.Write([]byte(`{"items":`)) //nolint:errcheck
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestKubernetesChecker_Check(t *testing.T) {
+	server := startKubernetesServer(t)
+
+	cfg := &KubeConfig{
+		Server:    server.URL,
+		Token:     "token",
+		Namespace: "prod",
+		TLS:       server.Client().Transport.(*http.Transport).TLSClientConfig,
+	}
+
+	tests := []struct {
+		name       string
+		kind       KubernetesKind
+		namespace  string
+		service    string
+		selector   string
+		minReady   int
+		wantState  gslb.State
+		wantStatus string
+	}{
+		{
+			name:       "ready endpoints",
+			kind:       KubernetesEndpoints,
+			service:    "api",
+			minReady:   2,
+			wantState:  gslb.StateDegraded,
+			wantStatus: "2 of 3 endpoints of service prod/api ready, not ready: api-b",
+		},
+		{
+			name:       "too few ready endpoints",
+			kind:       KubernetesEndpoints,
+			service:    "api",
+			minReady:   3,
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: "2 of 3 endpoints of service prod/api ready, 3 required, not ready: api-b",
+		},
+		{
+			name:       "no endpoints",
+			kind:       KubernetesEndpoints,
+			service:    "web",
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: "0 of 0 endpoints of service prod/web ready, 1 required",
+		},
+		{
+			name:       "forbidden namespace",
+			kind:       KubernetesEndpoints,
+			namespace:  "kube-system",
+			service:    "api",
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: "listing endpoints of service kube-system/api: 403 Forbidden",
+		},
+		{
+			name:       "ready nodes",
+			kind:       KubernetesNodes,
+			selector:   "role=worker",
+			wantState:  gslb.StateDegraded,
+			wantStatus: "1 of 2 nodes matching role=worker ready, not ready: worker-2",
+		},
+		{
+			name:       "invalid response",
+			kind:       KubernetesNodes,
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: "decoding response: unexpected EOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewKubernetesChecker(cfg, tt.kind)
+			c.Namespace = tt.namespace
+			c.Service = tt.service
+			c.Selector = tt.selector
+			if tt.minReady > 0 {
+				c.MinReady = tt.minReady
+			}
+
+			res, err := c.Check(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState {
+				t.Errorf("expected %s, got %s: %s", tt.wantState, res.State, res.Status)
+			}
+			if res.Status != tt.wantStatus {
+				t.Errorf("expected status %q, got %q", tt.wantStatus, res.Status)
+			}
+		})
+	}
+}
+
+func TestLoadKubeConfig(t *testing.T) {
+	server := startKubernetesServer(t)
+	dir := t.TempDir()
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte("token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	kubeconfig := `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: cluster
+  cluster:
+    server: ` + server.URL + `
+    certificate-authority-data: ` + base64.StdEncoding.EncodeToString(ca) + `
+users:
+- name: monitor
+  user:
+    tokenFile: token
+- name: sso
+  user:
+    exec:
+      command: kubelogin
+contexts:
+- name: prod
+  context:
+    cluster: cluster
+    user: monitor
+    namespace: prod
+- name: sso
+  context:
+    cluster: cluster
+    user: sso
+`
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadKubeConfig(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Namespace != "prod" || cfg.TokenFile != filepath.Join(dir, "token") {
+		t.Errorf("unexpected config: namespace %q, token file %q", cfg.Namespace, cfg.TokenFile)
+	}
+
+	c := NewKubernetesChecker(cfg, KubernetesEndpoints)
+	c.Service = "api"
+
+	res, err := c.Check(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.Details["ready"] != "2" {
+		t.Errorf("expected 2 ready endpoints, got %s: %s", res.State, res.Status)
+	}
+
+	if _, err := LoadKubeConfig(path, "sso"); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("expected unsupported plugin error, got %v", err)
+	}
+
+	if _, err := LoadKubeConfig(path, "missing"); err == nil {
+		t.Error("expected error for missing context")
+	}
+}
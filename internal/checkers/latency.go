@@ -0,0 +1,121 @@
+package checkers
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// LatencyThreshold judges response latencies, both of a single check and as
+// a rolling percentile over the most recent checks. Its zero value accepts
+// any latency.
+type LatencyThreshold struct {
+	// Max is the limit for a single response, zero disables it.
+	Max time.Duration
+
+	// PercentileMax is the limit for the given Percentile (e.g. 95) of the
+	// last Window responses, zero disables it.
+	Percentile    float64
+	PercentileMax time.Duration
+	Window        int
+
+	// Degrade reports a slow target as degraded but still healthy instead
+	// of unhealthy.
+	Degrade bool
+
+	mu      sync.Mutex
+	samples []time.Duration
+}
+
+const defaultLatencyWindow = 20
+
+// Validate reports configuration errors of the threshold.
+func (l *LatencyThreshold) Validate() error {
+	switch {
+	case l.Max < 0 || l.PercentileMax < 0:
+		return fmt.Errorf("latency limits must not be negative")
+	case l.PercentileMax > 0 && (l.Percentile <= 0 || l.Percentile > 100):
+		return fmt.Errorf("latency percentile must be between 0 and 100")
+	case l.Window < 0:
+		return fmt.Errorf("latency window must not be negative")
+	}
+
+	return nil
+}
+
+// Evaluate records latency and checks it against the limits. It returns
+// whether the target counts as healthy and, if a limit was exceeded, a
+// description of the violation.
+func (l *LatencyThreshold) Evaluate(latency time.Duration) (bool, string) {
+	if l == nil {
+		return true, ""
+	}
+
+	var violation string
+
+	if l.Max > 0 && latency > l.Max {
+		violation = fmt.Sprintf("latency %s exceeds %s", formatLatency(latency), l.Max)
+	}
+
+	if p, ok := l.record(latency); ok && p > l.PercentileMax && violation == "" {
+		violation = fmt.Sprintf("p%g latency %s exceeds %s", l.Percentile, formatLatency(p), l.PercentileMax)
+	}
+
+	switch {
+	case violation == "":
+		return true, ""
+	case l.Degrade:
+		return true, "degraded, " + violation
+	default:
+		return false, violation
+	}
+}
+
+// record adds a sample to the rolling window and returns the percentile if
+// a percentile limit is configured.
+func (l *LatencyThreshold) record(latency time.Duration) (time.Duration, bool) {
+	if l.PercentileMax <= 0 {
+		return 0, false
+	}
+
+	window := l.Window
+	if window <= 0 {
+		window = defaultLatencyWindow
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.samples = append(l.samples, latency)
+	if len(l.samples) > window {
+		l.samples = l.samples[len(l.samples)-window:]
+	}
+
+	return percentile(l.samples, l.Percentile), true
+}
+
+// percentile returns the nearest-rank percentile p (0-100) of samples.
+func percentile(samples []time.Duration, p float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = min(max(rank, 1), len(sorted))
+
+	return sorted[rank-1]
+}
+
+// formatLatency rounds latencies for status messages.
+func formatLatency(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(10 * time.Millisecond).String()
+	}
+
+	return d.Round(100 * time.Microsecond).String()
+}
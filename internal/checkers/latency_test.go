@@ -0,0 +1,170 @@
+package checkers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatencyThreshold_Evaluate(t *testing.T) {
+	tests := []struct {
+		name        string
+		threshold   *LatencyThreshold
+		latencies   []time.Duration
+		wantHealthy bool
+		wantStatus  string
+	}{
+		{
+			name:        "nil threshold",
+			latencies:   []time.Duration{time.Hour},
+			wantHealthy: true,
+		},
+		{
+			name:        "below max",
+			threshold:   &LatencyThreshold{Max: time.Second},
+			latencies:   []time.Duration{900 * time.Millisecond},
+			wantHealthy: true,
+		},
+		{
+			name:        "above max",
+			threshold:   &LatencyThreshold{Max: time.Second},
+			latencies:   []time.Duration{8 * time.Second},
+			wantHealthy: false,
+			wantStatus:  "latency 8s exceeds 1s",
+		},
+		{
+			name:        "above max degrades",
+			threshold:   &LatencyThreshold{Max: time.Second, Degrade: true},
+			latencies:   []time.Duration{8 * time.Second},
+			wantHealthy: true,
+			wantStatus:  "degraded, latency 8s exceeds 1s",
+		},
+		{
+			name:      "single outlier below percentile",
+			threshold: &LatencyThreshold{Percentile: 90, PercentileMax: time.Second, Window: 10},
+			latencies: []time.Duration{
+				100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond,
+				100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond,
+				100 * time.Millisecond, 5 * time.Second,
+			},
+			wantHealthy: true,
+		},
+		{
+			name:      "percentile above limit",
+			threshold: &LatencyThreshold{Percentile: 50, PercentileMax: time.Second, Window: 4},
+			latencies: []time.Duration{
+				100 * time.Millisecond, 2 * time.Second, 3 * time.Second, 2 * time.Second,
+			},
+			wantHealthy: false,
+			wantStatus:  "p50 latency 2s exceeds 1s",
+		},
+		{
+			name:      "slow samples leave the window",
+			threshold: &LatencyThreshold{Percentile: 50, PercentileMax: time.Second, Window: 2},
+			latencies: []time.Duration{
+				3 * time.Second, 3 * time.Second, 100 * time.Millisecond, 100 * time.Millisecond,
+			},
+			wantHealthy: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var healthy bool
+			var status string
+
+			for _, l := range tt.latencies {
+				healthy, status = tt.threshold.Evaluate(l)
+			}
+
+			if healthy != tt.wantHealthy {
+				t.Errorf("Evaluate() healthy = %v, want %v", healthy, tt.wantHealthy)
+			}
+
+			if status != tt.wantStatus {
+				t.Errorf("Evaluate() status = %q, want %q", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestLatencyThreshold_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold *LatencyThreshold
+		wantErr   bool
+	}{
+		{name: "zero value", threshold: &LatencyThreshold{}},
+		{name: "percentile", threshold: &LatencyThreshold{Percentile: 95, PercentileMax: time.Second}},
+		{name: "negative max", threshold: &LatencyThreshold{Max: -time.Second}, wantErr: true},
+		{name: "missing percentile", threshold: &LatencyThreshold{PercentileMax: time.Second}, wantErr: true},
+		{name: "percentile too large", threshold: &LatencyThreshold{Percentile: 101, PercentileMax: time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.threshold.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSimpleHTTPChecker_CheckHealth_Latency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Run("latency reported in status", func(t *testing.T) {
+		checker := NewSimpleHTTPChecker(server.URL, false)
+
+		healthy, statusMsg, err := checker.CheckHealth(context.Background())
+		if err != nil {
+			t.Fatalf("CheckHealth() unexpected error: %v", err)
+		}
+		if !healthy {
+			t.Errorf("CheckHealth() healthy = false, want true (status %q)", statusMsg)
+		}
+		if !latencyPattern.MatchString(statusMsg) {
+			t.Errorf("CheckHealth() statusMsg = %q, want latency", statusMsg)
+		}
+	})
+
+	t.Run("slow response unhealthy", func(t *testing.T) {
+		checker := NewSimpleHTTPChecker(server.URL, false)
+		checker.Retry = RetryPolicy{Attempts: 2, Timeout: time.Second, Delay: time.Millisecond}
+		checker.Latency = &LatencyThreshold{Max: 10 * time.Millisecond}
+
+		healthy, statusMsg, err := checker.CheckHealth(context.Background())
+		if err != nil {
+			t.Fatalf("CheckHealth() unexpected error: %v", err)
+		}
+		if healthy {
+			t.Errorf("CheckHealth() healthy = true, want false")
+		}
+		if !strings.Contains(statusMsg, "exceeds 10ms") {
+			t.Errorf("CheckHealth() statusMsg = %q, want latency violation", statusMsg)
+		}
+	})
+
+	t.Run("slow response degraded", func(t *testing.T) {
+		checker := NewSimpleHTTPChecker(server.URL, false)
+		checker.Latency = &LatencyThreshold{Max: 10 * time.Millisecond, Degrade: true}
+
+		healthy, statusMsg, err := checker.CheckHealth(context.Background())
+		if err != nil {
+			t.Fatalf("CheckHealth() unexpected error: %v", err)
+		}
+		if !healthy {
+			t.Errorf("CheckHealth() healthy = false, want true")
+		}
+		if !strings.Contains(statusMsg, "degraded") {
+			t.Errorf("CheckHealth() statusMsg = %q, want degraded", statusMsg)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// LDAPChecker binds to an LDAP server, anonymously or with a DN and
+// password, and reads the root DSE.
+type LDAPChecker struct {
+	Address string
+
+	// BindDN is bound with the password read from PasswordFile, an
+	// anonymous bind is done if empty.
+	BindDN string
+
+	// PasswordFile is read on every check, so rotated passwords are picked
+	// up without a restart.
+	PasswordFile string
+
+	TLSMode       TLSMode
+	SkipTLSVerify bool
+	ServerName    string
+	TLSFiles      *TLSFiles
+	MinTLSVersion uint16
+
+	Retry RetryPolicy
+}
+
+func NewLDAPChecker(address string) *LDAPChecker {
+	return &LDAPChecker{
+		Address: address,
+		Retry:   SingleAttemptPolicy(5 * time.Second),
+	}
+}
+
+// Check implements gslb.HealthChecker.
+func (c *LDAPChecker) Check(ctx context.Context) (gslb.Result, error) {
+	// Validate address before attempting to connect
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return gslb.Result{}, err
+	}
+
+	password, err := readSecretFile(c.PasswordFile)
+	if err != nil {
+		return gslb.Result{}, err
+	}
+
+	if c.BindDN != "" && password == "" {
+		return gslb.Result{}, fmt.Errorf("password must be set for bind DN %s", c.BindDN)
+	}
+
+	cfg, err := protocolTLSConfig(c.Address, c.SkipTLSVerify, c.ServerName, c.MinTLSVersion, c.TLSFiles)
+	if err != nil {
+		return gslb.Result{}, err
+	}
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		return c.check(ctx, cfg, password)
+	})
+}
+
+func (c *LDAPChecker) check(ctx context.Context, cfg *tls.Config, password string) gslb.Result {
+	start := time.Now()
+
+	conn, err := dialProtocol(ctx, c.Address, c.TLSMode, cfg)
+	if err != nil {
+		return failure(errorClass(err), err.Error(), time.Since(start))
+	}
+
+	l := ldap.NewConn(conn, c.TLSMode == TLSImplicit)
+	l.Start()
+	defer l.Close() //nolint:errcheck
+
+	if deadline, ok := ctx.Deadline(); ok {
+		l.SetTimeout(time.Until(deadline))
+	}
+
+	status := "root DSE read"
+
+	if c.TLSMode == TLSStartTLS {
+		if err := l.StartTLS(cfg); err != nil {
+			class := gslb.ErrorTLS
+			if ldapErrorClass(err) == gslb.ErrorResponse {
+				class = gslb.ErrorResponse
+			}
+
+			return failure(class, fmt.Sprintf("StartTLS: %s", err), time.Since(start))
+		}
+
+		status += " after StartTLS"
+	}
+
+	if c.BindDN == "" {
+		err = l.UnauthenticatedBind("")
+	} else {
+		err = l.Bind(c.BindDN, password)
+	}
+
+	if err != nil {
+		return failure(ldapErrorClass(err), fmt.Sprintf("bind: %s", err), time.Since(start))
+	}
+
+	sr, err := l.Search(ldap.NewSearchRequest(
+		"", ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, 0, false,
+		"(objectClass=*)", []string{"namingContexts", "vendorName", "supportedLDAPVersion"}, nil,
+	))
+	latency := time.Since(start)
+
+	if err != nil {
+		return failure(ldapErrorClass(err), fmt.Sprintf("root DSE search: %s", err), latency)
+	}
+
+	if len(sr.Entries) == 0 {
+		return failure(gslb.ErrorResponse, "root DSE search returned no entry", latency)
+	}
+
+	dse := sr.Entries[0]
+	contexts := dse.GetAttributeValues("namingContexts")
+
+	res := gslb.Result{
+		State:   gslb.StateHealthy,
+		Status:  fmt.Sprintf("%s in %s", status, formatLatency(latency)),
+		Latency: latency,
+		Details: map[string]string{"naming_contexts": strings.Join(contexts, ";")},
+	}
+
+	if vendor := dse.GetAttributeValue("vendorName"); vendor != "" {
+		res.Details["vendor"] = vendor
+	}
+
+	if len(contexts) > 0 {
+		res.Status += ": " + strings.Join(contexts, ", ")
+	}
+
+	return res
+}
+
+// ldapErrorClass tells results reported by the server, such as invalid
+// credentials, from connection errors.
+func ldapErrorClass(err error) gslb.ErrorClass {
+	var ldapErr *ldap.Error
+	if errors.As(err, &ldapErr) && ldapErr.ResultCode != ldap.ErrorNetwork {
+		return gslb.ErrorResponse
+	}
+
+	return errorClass(err)
+}
@@ -0,0 +1,144 @@
+package checkers
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// ldapResponse encodes an LDAP message with the given protocol op.
+func ldapResponse(id int64, op *ber.Packet) []byte {
+	msg := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	msg.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "MessageID"))
+	msg.AppendChild(op)
+
+	return msg.Bytes()
+}
+
+// ldapResult encodes an LDAPResult of the given application tag.
+func ldapResult(tag ber.Tag, code int64, message string) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "resultCode"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "diagnosticMessage"))
+
+	return op
+}
+
+// serveLDAP answers binds and root DSE searches like an LDAP server. Binds
+// with the DN cn=monitor,dc=example,dc=com only succeed with password
+// secret.
+func serveLDAP(conn net.Conn) {
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		id := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case 0: // BindRequest
+			dn := op.Children[1].Data.String()
+			password := op.Children[2].Data.String()
+
+			code, message := int64(0), ""
+			if dn != "" && (dn != "cn=monitor,dc=example,dc=com" || password != "secret") {
+				code, message = 49, "invalid credentials"
+			}
+
+			conn.Write(ldapResponse(id, ldapResult(1, code, message))) //nolint:errcheck
+		case 3: // SearchRequest
+			entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, 4, nil, "SearchResultEntry")
+			entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "objectName"))
+
+			attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+			attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attribute")
+			attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "namingContexts", "type"))
+			vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "vals")
+			vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "dc=example,dc=com", "value"))
+			attr.AppendChild(vals)
+			attrs.AppendChild(attr)
+			entry.AppendChild(attrs)
+
+			conn.Write(ldapResponse(id, entry))                //nolint:errcheck
+			conn.Write(ldapResponse(id, ldapResult(5, 0, ""))) //nolint:errcheck
+		default: // UnbindRequest
+			return
+		}
+	}
+}
+
+func TestLDAPChecker_Check(t *testing.T) {
+	addr := startTCPServer(t, serveLDAP)
+
+	tests := []struct {
+		name       string
+		bindDN     string
+		password   string
+		wantState  gslb.State
+		wantClass  gslb.ErrorClass
+		wantStatus string
+	}{
+		{
+			name:       "anonymous bind",
+			wantState:  gslb.StateHealthy,
+			wantStatus: "root DSE read in",
+		},
+		{
+			name:       "simple bind",
+			bindDN:     "cn=monitor,dc=example,dc=com",
+			password:   "secret",
+			wantState:  gslb.StateHealthy,
+			wantStatus: "root DSE read in",
+		},
+		{
+			name:       "invalid credentials",
+			bindDN:     "cn=monitor,dc=example,dc=com",
+			password:   "wrong",
+			wantState:  gslb.StateUnhealthy,
+			wantClass:  gslb.ErrorResponse,
+			wantStatus: "bind: LDAP Result Code 49",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewLDAPChecker(addr)
+			c.BindDN = tt.bindDN
+			if tt.password != "" {
+				c.PasswordFile = writeSecret(t, tt.password)
+			}
+
+			res, err := c.Check(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState || res.Class != tt.wantClass {
+				t.Errorf("expected %s (%q), got %s (%q): %s", tt.wantState, tt.wantClass, res.State, res.Class, res.Status)
+			}
+			if !strings.HasPrefix(res.Status, tt.wantStatus) {
+				t.Errorf("expected status starting with %q, got %q", tt.wantStatus, res.Status)
+			}
+			if tt.wantState == gslb.StateHealthy && res.Details["naming_contexts"] != "dc=example,dc=com" {
+				t.Errorf("expected naming context, got %q", res.Details["naming_contexts"])
+			}
+		})
+	}
+}
+
+func TestLDAPChecker_Check_MissingPassword(t *testing.T) {
+	c := NewLDAPChecker("127.0.0.1:389")
+	c.BindDN = "cn=monitor,dc=example,dc=com"
+
+	if _, err := c.Check(context.Background()); err == nil {
+		t.Error("expected error for bind DN without password")
+	}
+}
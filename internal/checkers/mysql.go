@@ -0,0 +1,99 @@
+package checkers
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// MySQLChecker connects to a MySQL server and pings it.
+type MySQLChecker struct {
+	Address  string
+	Database string
+	User     string
+
+	// PasswordFile is read on every check, so rotated passwords are picked
+	// up without a restart.
+	PasswordFile string
+
+	Retry RetryPolicy
+}
+
+func NewMySQLChecker(address, database, user string) *MySQLChecker {
+	return &MySQLChecker{
+		Address:  address,
+		Database: database,
+		User:     user,
+		Retry:    SingleAttemptPolicy(5 * time.Second),
+	}
+}
+
+// Check implements gslb.HealthChecker.
+func (c *MySQLChecker) Check(ctx context.Context) (gslb.Result, error) {
+	// Validate address before attempting to connect
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return gslb.Result{}, err
+	}
+
+	password, err := readSecretFile(c.PasswordFile)
+	if err != nil {
+		return gslb.Result{}, err
+	}
+
+	cfg := mysql.NewConfig()
+	cfg.Net = "tcp"
+	cfg.Addr = c.Address
+	cfg.DBName = c.Database
+	cfg.User = c.User
+	cfg.Passwd = password
+	cfg.Logger = &mysql.NopLogger{}
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return gslb.Result{}, fmt.Errorf("creating MySQL connector: %w", err)
+	}
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		return c.check(ctx, connector)
+	})
+}
+
+func (c *MySQLChecker) check(ctx context.Context, connector driver.Connector) gslb.Result {
+	start := time.Now()
+
+	conn, err := connector.Connect(ctx)
+	if err != nil {
+		return failure(mysqlErrorClass(err), err.Error(), time.Since(start))
+	}
+	defer conn.Close() //nolint:errcheck
+
+	if err := conn.(driver.Pinger).Ping(ctx); err != nil {
+		return failure(mysqlErrorClass(err), err.Error(), time.Since(start))
+	}
+
+	latency := time.Since(start)
+
+	return gslb.Result{
+		State:   gslb.StateHealthy,
+		Status:  fmt.Sprintf("ping succeeded in %s", formatLatency(latency)),
+		Latency: latency,
+	}
+}
+
+// mysqlErrorClass tells errors reported by the server, such as failed
+// authentication, from connection errors.
+func mysqlErrorClass(err error) gslb.ErrorClass {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return gslb.ErrorResponse
+	}
+
+	return errorClass(err)
+}
@@ -0,0 +1,172 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// mysqlSalt is the authentication challenge of the MySQL stand-in.
+var mysqlSalt = []byte("abcdefghijklmnopqrst")
+
+// startMySQLServer starts a MySQL stand-in that accepts password through
+// mysql_native_password and answers pings.
+func startMySQLServer(t *testing.T, password string) string {
+	t.Helper()
+
+	return startTCPServer(t, func(c net.Conn) {
+		// Protocol 41, secure connection and plugin auth
+		const capabilities = 0x0200 | 0x8000 | 0x0008 | 0x80000
+
+		var hs bytes.Buffer
+		hs.WriteByte(10)
+		hs.WriteString("8.0.0-standin\x00")
+		binary.Write(&hs, binary.LittleEndian, uint32(1)) //nolint:errcheck
+		hs.Write(mysqlSalt[:8])
+		hs.WriteByte(0)
+		binary.Write(&hs, binary.LittleEndian, uint16(capabilities&0xffff)) //nolint:errcheck
+		hs.WriteByte(0x21)
+		binary.Write(&hs, binary.LittleEndian, uint16(2))                //nolint:errcheck
+		binary.Write(&hs, binary.LittleEndian, uint16(capabilities>>16)) //nolint:errcheck
+		hs.WriteByte(byte(len(mysqlSalt) + 1))
+		hs.Write(make([]byte, 10))
+		hs.Write(mysqlSalt[8:])
+		hs.WriteByte(0)
+		hs.WriteString("mysql_native_password\x00")
+
+		if err := writeMySQLPacket(c, 0, hs.Bytes()); err != nil {
+			return
+		}
+
+		seq, resp, err := readMySQLPacket(c)
+		if err != nil {
+			return
+		}
+
+		if !bytes.Contains(resp, mysqlNativePassword(password)) {
+			writeMySQLPacket(c, seq+1, append([]byte{0xff, 0x15, 0x04, '#'}, "28000Access denied for user"...)) //nolint:errcheck
+			return
+		}
+
+		ok := []byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00}
+		if err := writeMySQLPacket(c, seq+1, ok); err != nil {
+			return
+		}
+
+		for {
+			seq, cmd, err := readMySQLPacket(c)
+			if err != nil || len(cmd) == 0 || cmd[0] != 0x0e {
+				// Anything but COM_PING, e.g. COM_QUIT, ends the session
+				return
+			}
+
+			if err := writeMySQLPacket(c, seq+1, ok); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// mysqlNativePassword computes the mysql_native_password scramble.
+func mysqlNativePassword(password string) []byte {
+	stage1 := sha1.Sum([]byte(password))
+	stage2 := sha1.Sum(stage1[:])
+	mix := sha1.Sum(append(append([]byte{}, mysqlSalt...), stage2[:]...))
+
+	for i := range mix {
+		mix[i] ^= stage1[i]
+	}
+
+	return mix[:]
+}
+
+func writeMySQLPacket(w io.Writer, seq byte, payload []byte) error {
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), seq}
+	_, err := w.Write(append(header, payload...))
+	return err
+}
+
+func readMySQLPacket(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+
+	payload := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	return header[3], payload, nil
+}
+
+func TestMySQLChecker_Check(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientSecret string
+		wantState    gslb.State
+		wantClass    gslb.ErrorClass
+		wantStatus   string
+	}{
+		{
+			name:         "ping",
+			clientSecret: "s3cret",
+			wantState:    gslb.StateHealthy,
+			wantStatus:   "ping succeeded",
+		},
+		{
+			name:         "wrong password",
+			clientSecret: "guess",
+			wantState:    gslb.StateUnhealthy,
+			wantClass:    gslb.ErrorResponse,
+			wantStatus:   "Access denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewMySQLChecker(startMySQLServer(t, "s3cret"), "app", "monitor")
+			checker.PasswordFile = writeSecret(t, tt.clientSecret)
+
+			res, err := checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState {
+				t.Errorf("Check() state = %v, want %v (status %q)", res.State, tt.wantState, res.Status)
+			}
+			if res.Class != tt.wantClass {
+				t.Errorf("Check() class = %q, want %q", res.Class, tt.wantClass)
+			}
+			if !strings.Contains(res.Status, tt.wantStatus) {
+				t.Errorf("Check() status = %q, want %q", res.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestMySQLChecker_Check_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker *MySQLChecker
+	}{
+		{"invalid address", NewMySQLChecker("localhost", "app", "monitor")},
+		{"missing password file", &MySQLChecker{Address: "localhost:3306", PasswordFile: "/nonexistent/password"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.checker.Check(context.Background()); err == nil {
+				t.Errorf("Check() expected error, got nil")
+			}
+		})
+	}
+}
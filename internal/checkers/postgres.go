@@ -0,0 +1,141 @@
+package checkers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// PostgresChecker connects to a PostgreSQL server and runs SELECT 1.
+type PostgresChecker struct {
+	Address  string
+	Database string
+	User     string
+
+	// PasswordFile is read on every check, so rotated passwords are picked
+	// up without a restart.
+	PasswordFile string
+
+	// SSLMode is passed to the server as sslmode, "prefer" if empty.
+	SSLMode string
+
+	// RequirePrimary reports read-only standby servers as unhealthy.
+	RequirePrimary bool
+
+	Retry RetryPolicy
+}
+
+func NewPostgresChecker(address, database, user string) *PostgresChecker {
+	return &PostgresChecker{
+		Address:  address,
+		Database: database,
+		User:     user,
+		Retry:    SingleAttemptPolicy(5 * time.Second),
+	}
+}
+
+// Check implements gslb.HealthChecker.
+func (c *PostgresChecker) Check(ctx context.Context) (gslb.Result, error) {
+	// Validate address before attempting to connect
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return gslb.Result{}, err
+	}
+
+	password, err := readSecretFile(c.PasswordFile)
+	if err != nil {
+		return gslb.Result{}, err
+	}
+
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "prefer"
+	}
+
+	connURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.User(c.User),
+		Host:     c.Address,
+		Path:     "/" + c.Database,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+
+	cfg, err := pgconn.ParseConfig(connURL.String())
+	if err != nil {
+		return gslb.Result{}, fmt.Errorf("parsing PostgreSQL config: %w", err)
+	}
+
+	cfg.Password = password
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		return c.check(ctx, cfg)
+	})
+}
+
+func (c *PostgresChecker) check(ctx context.Context, cfg *pgconn.Config) gslb.Result {
+	start := time.Now()
+
+	conn, err := pgconn.ConnectConfig(ctx, cfg)
+	if err != nil {
+		return failure(postgresErrorClass(err), err.Error(), time.Since(start))
+	}
+	defer conn.Close(context.Background()) //nolint:errcheck
+
+	if _, err := postgresQuery(ctx, conn, "SELECT 1"); err != nil {
+		return failure(postgresErrorClass(err), err.Error(), time.Since(start))
+	}
+
+	status := "SELECT 1 succeeded"
+
+	if c.RequirePrimary {
+		recovery, err := postgresQuery(ctx, conn, "SELECT pg_is_in_recovery()")
+		if err != nil {
+			return failure(postgresErrorClass(err), err.Error(), time.Since(start))
+		}
+
+		if recovery == "t" {
+			return failure(gslb.ErrorResponse, "server is a read-only standby", time.Since(start))
+		}
+
+		status += " on primary"
+	}
+
+	latency := time.Since(start)
+
+	return gslb.Result{
+		State:   gslb.StateHealthy,
+		Status:  fmt.Sprintf("%s in %s", status, formatLatency(latency)),
+		Latency: latency,
+	}
+}
+
+// postgresQuery runs query and returns the first column of the first row.
+func postgresQuery(ctx context.Context, conn *pgconn.PgConn, query string) (string, error) {
+	results, err := conn.Exec(ctx, query).ReadAll()
+	if err != nil {
+		return "", err
+	}
+
+	if len(results) == 0 || len(results[0].Rows) == 0 || len(results[0].Rows[0]) == 0 {
+		return "", fmt.Errorf("%s returned no rows", query)
+	}
+
+	return string(results[0].Rows[0][0]), nil
+}
+
+// postgresErrorClass tells errors reported by the server, such as failed
+// authentication, from connection errors.
+func postgresErrorClass(err error) gslb.ErrorClass {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return gslb.ErrorResponse
+	}
+
+	return errorClass(err)
+}
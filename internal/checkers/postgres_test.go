@@ -0,0 +1,199 @@
+package checkers
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// startPostgresServer starts a PostgreSQL stand-in accepting password and
+// answering every query with a single value, "t" for pg_is_in_recovery() if
+// standby is set.
+func startPostgresServer(t *testing.T, password string, standby bool) string {
+	t.Helper()
+
+	return startTCPServer(t, func(c net.Conn) {
+		be := pgproto3.NewBackend(c, c)
+
+		msg, err := be.ReceiveStartupMessage()
+		if err != nil {
+			return
+		}
+
+		// Decline TLS, the client falls back to plaintext
+		if _, ok := msg.(*pgproto3.SSLRequest); ok {
+			if _, err := c.Write([]byte("N")); err != nil {
+				return
+			}
+			if _, err := be.ReceiveStartupMessage(); err != nil {
+				return
+			}
+		}
+
+		be.Send(&pgproto3.AuthenticationCleartextPassword{})
+		if err := be.Flush(); err != nil {
+			return
+		}
+		if err := be.SetAuthType(pgproto3.AuthTypeCleartextPassword); err != nil {
+			return
+		}
+
+		msg, err = be.Receive()
+		if err != nil {
+			return
+		}
+		if pw, ok := msg.(*pgproto3.PasswordMessage); !ok || pw.Password != password {
+			be.Send(&pgproto3.ErrorResponse{Severity: "FATAL", Code: "28P01", Message: "password authentication failed"})
+			be.Flush() //nolint:errcheck
+			return
+		}
+
+		be.Send(&pgproto3.AuthenticationOk{})
+		be.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+		if err := be.Flush(); err != nil {
+			return
+		}
+
+		for {
+			msg, err := be.Receive()
+			if err != nil {
+				return
+			}
+
+			query, ok := msg.(*pgproto3.Query)
+			if !ok {
+				return
+			}
+
+			value := "1"
+			if strings.Contains(query.String, "pg_is_in_recovery") {
+				value = "f"
+				if standby {
+					value = "t"
+				}
+			}
+
+			be.Send(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{{Name: []byte("?column?"), DataTypeOID: 25}}})
+			be.Send(&pgproto3.DataRow{Values: [][]byte{[]byte(value)}})
+			be.Send(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")})
+			be.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if err := be.Flush(); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func TestPostgresChecker_Check(t *testing.T) {
+	tests := []struct {
+		name           string
+		standby        bool
+		clientSecret   string
+		requirePrimary bool
+		wantState      gslb.State
+		wantClass      gslb.ErrorClass
+		wantStatus     string
+	}{
+		{
+			name:         "select",
+			clientSecret: "s3cret",
+			wantState:    gslb.StateHealthy,
+			wantStatus:   "SELECT 1 succeeded",
+		},
+		{
+			name:         "standby accepted",
+			standby:      true,
+			clientSecret: "s3cret",
+			wantState:    gslb.StateHealthy,
+			wantStatus:   "SELECT 1 succeeded",
+		},
+		{
+			name:           "primary required",
+			clientSecret:   "s3cret",
+			requirePrimary: true,
+			wantState:      gslb.StateHealthy,
+			wantStatus:     "SELECT 1 succeeded on primary",
+		},
+		{
+			name:           "standby rejected",
+			standby:        true,
+			clientSecret:   "s3cret",
+			requirePrimary: true,
+			wantState:      gslb.StateUnhealthy,
+			wantClass:      gslb.ErrorResponse,
+			wantStatus:     "read-only standby",
+		},
+		{
+			name:         "wrong password",
+			clientSecret: "guess",
+			wantState:    gslb.StateUnhealthy,
+			wantClass:    gslb.ErrorResponse,
+			wantStatus:   "password authentication failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewPostgresChecker(startPostgresServer(t, "s3cret", tt.standby), "app", "monitor")
+			checker.PasswordFile = writeSecret(t, tt.clientSecret)
+			checker.RequirePrimary = tt.requirePrimary
+
+			res, err := checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState {
+				t.Errorf("Check() state = %v, want %v (status %q)", res.State, tt.wantState, res.Status)
+			}
+			if res.Class != tt.wantClass {
+				t.Errorf("Check() class = %q, want %q", res.Class, tt.wantClass)
+			}
+			if !strings.Contains(res.Status, tt.wantStatus) {
+				t.Errorf("Check() status = %q, want %q", res.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPostgresChecker_Check_Unreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close() //nolint:errcheck
+
+	res, err := NewPostgresChecker(addr, "app", "monitor").Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if res.State != gslb.StateUnhealthy || res.Class != gslb.ErrorConnection {
+		t.Errorf("Check() = %v/%q, want unhealthy connection (status %q)", res.State, res.Class, res.Status)
+	}
+}
+
+func TestPostgresChecker_Check_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker *PostgresChecker
+	}{
+		{"invalid address", NewPostgresChecker("localhost", "app", "monitor")},
+		{"missing password file", &PostgresChecker{Address: "localhost:5432", PasswordFile: "/nonexistent/password"}},
+		{"invalid sslmode", &PostgresChecker{Address: "localhost:5432", SSLMode: "sometimes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.checker.Check(context.Background()); err == nil {
+				t.Errorf("Check() expected error, got nil")
+			}
+		})
+	}
+}
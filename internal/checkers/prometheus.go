@@ -0,0 +1,348 @@
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+type PrometheusMode string
+
+const (
+	// PrometheusThreshold compares every value of the result with the
+	// threshold, the target is healthy if all of them pass.
+	PrometheusThreshold PrometheusMode = "threshold"
+	// PrometheusEmpty treats any returned series as unhealthy, e.g. for a
+	// query returning firing alerts.
+	PrometheusEmpty PrometheusMode = "empty"
+)
+
+// PrometheusNoData selects how threshold mode handles NaN values and
+// results without data, e.g. an error ratio of 0/0 without traffic.
+type PrometheusNoData string
+
+const (
+	// PrometheusNoDataUnhealthy fails NaN values, a result without data is
+	// unknown. This is the default.
+	PrometheusNoDataUnhealthy PrometheusNoData = "unhealthy"
+	// PrometheusNoDataHealthy passes NaN values and results without data.
+	PrometheusNoDataHealthy PrometheusNoData = "healthy"
+	// PrometheusNoDataIgnore skips NaN values, a result without any other
+	// value is unknown.
+	PrometheusNoDataIgnore PrometheusNoData = "ignore"
+)
+
+// maxReportedSeries limits how many failing series are listed in the status.
+const maxReportedSeries = 5
+
+// PrometheusChecker runs an instant query against a Prometheus compatible
+// HTTP API.
+type PrometheusChecker struct {
+	// URL is the base URL of the API, e.g. http://prometheus:9090.
+	URL   string
+	Query string
+	Mode  PrometheusMode
+
+	// Op and Threshold form the condition every value has to meet in
+	// threshold mode, e.g. "<" and 0.02.
+	Op        string
+	Threshold float64
+	// NoData handles NaN values and empty results in threshold mode,
+	// defaults to PrometheusNoDataUnhealthy.
+	NoData PrometheusNoData
+
+	// Headers are added to every request, e.g. for authentication.
+	Headers http.Header
+
+	SkipTLSVerify bool
+	ServerName    string
+	TLSFiles      *TLSFiles
+	MinTLSVersion uint16
+
+	Retry RetryPolicy
+}
+
+func NewPrometheusChecker(url, query string) *PrometheusChecker {
+	return &PrometheusChecker{
+		URL:   url,
+		Query: query,
+		Mode:  PrometheusThreshold,
+		Retry: DefaultRetryPolicy(),
+	}
+}
+
+var prometheusConditionRe = regexp.MustCompile(`^\s*(==|!=|<=|>=|<|>)\s*(\S+)\s*$`)
+
+// ParsePrometheusCondition parses a condition like "< 0.02" into its
+// operator and threshold.
+func ParsePrometheusCondition(s string) (string, float64, error) {
+	m := prometheusConditionRe.FindStringSubmatch(s)
+	if m == nil {
+		return "", 0, fmt.Errorf("invalid condition %q, expected <op> <number>", s)
+	}
+
+	threshold, err := strconv.ParseFloat(m[2], 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid condition %q, %s is not a number", s, m[2])
+	}
+
+	return m[1], threshold, nil
+}
+
+// Validate reports configuration errors of the checker.
+func (c *PrometheusChecker) Validate() error {
+	switch {
+	case c.Query == "":
+		return fmt.Errorf("query must be set")
+	case c.Mode != PrometheusThreshold && c.Mode != PrometheusEmpty:
+		return fmt.Errorf("unsupported mode %q", c.Mode)
+	case c.Mode == PrometheusThreshold && !prometheusConditionRe.MatchString(c.Op+" 0"):
+		return fmt.Errorf("unsupported comparison operator %q", c.Op)
+	case c.NoData != "" && c.NoData != PrometheusNoDataUnhealthy && c.NoData != PrometheusNoDataHealthy && c.NoData != PrometheusNoDataIgnore:
+		return fmt.Errorf("unsupported no data handling %q", c.NoData)
+	}
+
+	return nil
+}
+
+// Check implements gslb.HealthChecker.
+func (c *PrometheusChecker) Check(ctx context.Context) (gslb.Result, error) {
+	if err := c.Validate(); err != nil {
+		return gslb.Result{}, err
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return gslb.Result{}, err
+	}
+
+	u = u.JoinPath("api/v1/query")
+	u.RawQuery = url.Values{"query": {c.Query}}.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return gslb.Result{}, fmt.Errorf("creating request: %w", err)
+	}
+
+	for name, values := range c.Headers {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+
+	tlsConfig, err := newTLSConfig(c.SkipTLSVerify, c.ServerName, c.MinTLSVersion, c.TLSFiles)
+	if err != nil {
+		return gslb.Result{}, err
+	}
+
+	transport := &http.Transport{TLSClientConfig: tlsConfig}
+	defer transport.CloseIdleConnections()
+
+	client := &http.Client{Transport: transport}
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		start := time.Now()
+
+		resp, err := client.Do(req.Clone(ctx))
+		if err != nil {
+			return failure(errorClass(err), err.Error(), time.Since(start))
+		}
+		defer resp.Body.Close() //nolint:errcheck
+
+		var qr prometheusResponse
+		err = json.NewDecoder(io.LimitReader(resp.Body, maxBodySize)).Decode(&qr)
+		latency := time.Since(start)
+
+		switch {
+		case err != nil && resp.StatusCode != http.StatusOK:
+			return failure(gslb.ErrorStatus, resp.Status, latency)
+		case err != nil:
+			return failure(gslb.ErrorResponse, fmt.Sprintf("decoding response: %s", err), latency)
+		case qr.Status != "success":
+			return failure(gslb.ErrorResponse, fmt.Sprintf("query failed: %s: %s", qr.ErrorType, qr.Error), latency)
+		}
+
+		res := c.evaluate(qr.Data)
+		res.Latency = latency
+
+		return res
+	})
+}
+
+type prometheusResponse struct {
+	Status    string         `json:"status"`
+	ErrorType string         `json:"errorType"`
+	Error     string         `json:"error"`
+	Data      prometheusData `json:"data"`
+}
+
+type prometheusData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+type prometheusSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  [2]any            `json:"value"`
+}
+
+// evaluate judges the query result according to the mode.
+func (c *PrometheusChecker) evaluate(data prometheusData) gslb.Result {
+	var samples []prometheusSample
+
+	switch data.ResultType {
+	case "scalar":
+		var value [2]any
+		if err := json.Unmarshal(data.Result, &value); err != nil {
+			return failure(gslb.ErrorResponse, fmt.Sprintf("decoding scalar: %s", err), 0)
+		}
+		samples = []prometheusSample{{Value: value}}
+	case "vector":
+		if err := json.Unmarshal(data.Result, &samples); err != nil {
+			return failure(gslb.ErrorResponse, fmt.Sprintf("decoding vector: %s", err), 0)
+		}
+	default:
+		return failure(gslb.ErrorResponse, fmt.Sprintf("unsupported result type %q", data.ResultType), 0)
+	}
+
+	details := map[string]string{"series": strconv.Itoa(len(samples))}
+
+	if c.Mode == PrometheusEmpty {
+		if len(samples) == 0 {
+			return gslb.Result{State: gslb.StateHealthy, Status: "query returned no series", Details: details}
+		}
+
+		series := make([]string, 0, len(samples))
+		for _, s := range samples {
+			series = append(series, formatSeries(s.Metric))
+		}
+
+		res := failure(gslb.ErrorResponse, fmt.Sprintf("query returned %d series: %s", len(samples), joinLimited(series)), 0)
+		res.Details = details
+
+		return res
+	}
+
+	if len(samples) == 0 {
+		return c.noData("query returned no data", details)
+	}
+
+	var failed []string
+	nan := 0
+	for _, s := range samples {
+		value := sampleValue(s.Value)
+		if len(samples) == 1 {
+			details["value"] = strconv.FormatFloat(value, 'g', -1, 64)
+		}
+
+		if math.IsNaN(value) && c.NoData != "" && c.NoData != PrometheusNoDataUnhealthy {
+			nan++
+			continue
+		}
+
+		if math.IsNaN(value) || !compareNumbers(value, c.Op, c.Threshold) {
+			entry := strconv.FormatFloat(value, 'g', -1, 64)
+			if len(s.Metric) > 0 {
+				entry = formatSeries(s.Metric) + " " + entry
+			}
+
+			failed = append(failed, entry)
+		}
+	}
+
+	if nan > 0 {
+		details["nan"] = strconv.Itoa(nan)
+	}
+
+	if nan == len(samples) {
+		return c.noData("query returned only NaN values", details)
+	}
+
+	condition := fmt.Sprintf("%s %g", c.Op, c.Threshold)
+
+	if len(failed) > 0 {
+		res := failure(gslb.ErrorResponse, fmt.Sprintf("%d of %d values not %s: %s", len(failed), len(samples), condition, joinLimited(failed)), 0)
+		res.Details = details
+
+		return res
+	}
+
+	status := fmt.Sprintf("all %d values %s", len(samples)-nan, condition)
+	if nan > 0 {
+		status += fmt.Sprintf(", %d NaN values ignored", nan)
+	}
+	if len(samples) == 1 {
+		status = fmt.Sprintf("value %s %s", details["value"], condition)
+	}
+
+	return gslb.Result{
+		State:   gslb.StateHealthy,
+		Status:  status,
+		Details: details,
+	}
+}
+
+// noData returns the result for a query without usable values.
+func (c *PrometheusChecker) noData(status string, details map[string]string) gslb.Result {
+	if c.NoData == PrometheusNoDataHealthy {
+		return gslb.Result{State: gslb.StateHealthy, Status: status, Details: details}
+	}
+
+	return gslb.Result{
+		State:   gslb.StateUnknown,
+		Class:   gslb.ErrorResponse,
+		Status:  status,
+		Details: details,
+	}
+}
+
+// sampleValue returns the value of a [timestamp, "value"] pair, NaN if it
+// is not a number.
+func sampleValue(v [2]any) float64 {
+	s, ok := v[1].(string)
+	if !ok {
+		return math.NaN()
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return math.NaN()
+	}
+
+	return f
+}
+
+// formatSeries formats labels like PromQL, e.g. up{job="api"}.
+func formatSeries(metric map[string]string) string {
+	name := metric["__name__"]
+
+	var labels []string
+	for k, v := range metric {
+		if k != "__name__" {
+			labels = append(labels, fmt.Sprintf("%s=%q", k, v))
+		}
+	}
+	slices.Sort(labels)
+
+	return name + "{" + strings.Join(labels, ",") + "}"
+}
+
+// joinLimited joins the first maxReportedSeries items.
+func joinLimited(items []string) string {
+	if len(items) > maxReportedSeries {
+		return strings.Join(items[:maxReportedSeries], ", ") + fmt.Sprintf(" and %d more", len(items)-maxReportedSeries)
+	}
+
+	return strings.Join(items, ", ")
+}
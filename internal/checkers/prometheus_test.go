@@ -0,0 +1,268 @@
+package checkers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// startPrometheusServer serves /api/v1/query, answering with the response
+// registered for the query.
+func startPrometheusServer(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		resp, ok := responses[r.URL.Query().Get("query")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			resp = `{"status":"error","errorType":"bad_data","error":"parse error"}`
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(resp)) //nolint:errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestPrometheusChecker_Check(t *testing.T) {
+	server := startPrometheusServer(t, map[string]string{
+		"error_ratio": `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"job":"api"},"value":[1700000000,"0.01"]}]}}`,
+		"error_ratio_high": `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"job":"api","instance":"a"},"value":[1700000000,"0.01"]},
+			{"metric":{"job":"api","instance":"b"},"value":[1700000000,"0.05"]}]}}`,
+		"scalar(1)": `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"1"]}}`,
+		"nan":       `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"NaN"]}}`,
+		"error_ratio_quiet": `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"job":"api","instance":"a"},"value":[1700000000,"0.01"]},
+			{"metric":{"job":"api","instance":"b"},"value":[1700000000,"NaN"]}]}}`,
+		"no_data": `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		"ALERTS": `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"__name__":"ALERTS","alertname":"PrimaryDown","alertstate":"firing"},"value":[1700000000,"1"]}]}}`,
+		"range": `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+	})
+
+	tests := []struct {
+		name       string
+		query      string
+		mode       PrometheusMode
+		condition  string
+		noData     PrometheusNoData
+		wantState  gslb.State
+		wantStatus string
+	}{
+		{
+			name:       "vector below threshold",
+			query:      "error_ratio",
+			condition:  "< 0.02",
+			wantState:  gslb.StateHealthy,
+			wantStatus: "value 0.01 < 0.02",
+		},
+		{
+			name:       "vector above threshold",
+			query:      "error_ratio_high",
+			condition:  "< 0.02",
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: `1 of 2 values not < 0.02: {instance="b",job="api"} 0.05`,
+		},
+		{
+			name:       "scalar",
+			query:      "scalar(1)",
+			condition:  "== 1",
+			wantState:  gslb.StateHealthy,
+			wantStatus: "value 1 == 1",
+		},
+		{
+			name:       "NaN fails",
+			query:      "nan",
+			condition:  "!= 1",
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: "NaN",
+		},
+		{
+			name:       "NaN healthy",
+			query:      "nan",
+			condition:  "< 0.02",
+			noData:     PrometheusNoDataHealthy,
+			wantState:  gslb.StateHealthy,
+			wantStatus: "only NaN values",
+		},
+		{
+			name:       "NaN ignored",
+			query:      "error_ratio_quiet",
+			condition:  "< 0.02",
+			noData:     PrometheusNoDataIgnore,
+			wantState:  gslb.StateHealthy,
+			wantStatus: "all 1 values < 0.02, 1 NaN values ignored",
+		},
+		{
+			name:       "only NaN ignored is unknown",
+			query:      "nan",
+			condition:  "< 0.02",
+			noData:     PrometheusNoDataIgnore,
+			wantState:  gslb.StateUnknown,
+			wantStatus: "only NaN values",
+		},
+		{
+			name:       "no data healthy",
+			query:      "no_data",
+			condition:  "< 0.02",
+			noData:     PrometheusNoDataHealthy,
+			wantState:  gslb.StateHealthy,
+			wantStatus: "no data",
+		},
+		{
+			name:       "no data is unknown",
+			query:      "no_data",
+			condition:  "< 0.02",
+			wantState:  gslb.StateUnknown,
+			wantStatus: "no data",
+		},
+		{
+			name:       "empty mode without series",
+			query:      "no_data",
+			mode:       PrometheusEmpty,
+			wantState:  gslb.StateHealthy,
+			wantStatus: "no series",
+		},
+		{
+			name:       "empty mode with firing alert",
+			query:      "ALERTS",
+			mode:       PrometheusEmpty,
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: `ALERTS{alertname="PrimaryDown",alertstate="firing"}`,
+		},
+		{
+			name:       "query error",
+			query:      "rate(",
+			condition:  "< 1",
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: "bad_data: parse error",
+		},
+		{
+			name:       "unsupported result type",
+			query:      "range",
+			condition:  "< 1",
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: `unsupported result type "matrix"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewPrometheusChecker(server.URL, tt.query)
+			checker.Headers = http.Header{"Authorization": {"Bearer token"}}
+			checker.Retry = SingleAttemptPolicy(time.Second)
+			checker.NoData = tt.noData
+			if tt.mode != "" {
+				checker.Mode = tt.mode
+			}
+			if tt.condition != "" {
+				var err error
+				if checker.Op, checker.Threshold, err = ParsePrometheusCondition(tt.condition); err != nil {
+					t.Fatalf("ParsePrometheusCondition() failed: %v", err)
+				}
+			}
+
+			res, err := checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState {
+				t.Errorf("Check() state = %v, want %v (status %q)", res.State, tt.wantState, res.Status)
+			}
+			if !strings.Contains(res.Status, tt.wantStatus) {
+				t.Errorf("Check() status = %q, want %q", res.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPrometheusChecker_Check_Unauthorized(t *testing.T) {
+	server := startPrometheusServer(t, nil)
+
+	checker := NewPrometheusChecker(server.URL, "up")
+	checker.Op = "=="
+	checker.Threshold = 1
+	checker.Retry = SingleAttemptPolicy(time.Second)
+
+	res, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if res.State != gslb.StateUnhealthy || res.Class != gslb.ErrorStatus {
+		t.Errorf("Check() = %v/%q, want unhealthy status (status %q)", res.State, res.Class, res.Status)
+	}
+}
+
+func TestParsePrometheusCondition(t *testing.T) {
+	tests := []struct {
+		input         string
+		wantOp        string
+		wantThreshold float64
+		wantErr       bool
+	}{
+		{"< 0.02", "<", 0.02, false},
+		{">=99.9", ">=", 99.9, false},
+		{"== 0", "==", 0, false},
+		{"0.02", "", 0, true},
+		{"< high", "", 0, true},
+		{"", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			op, threshold, err := ParsePrometheusCondition(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePrometheusCondition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if op != tt.wantOp || threshold != tt.wantThreshold {
+				t.Errorf("ParsePrometheusCondition() = %q %v, want %q %v", op, threshold, tt.wantOp, tt.wantThreshold)
+			}
+		})
+	}
+}
+
+func TestPrometheusChecker_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker *PrometheusChecker
+		wantErr bool
+	}{
+		{"valid threshold", &PrometheusChecker{Query: "up", Mode: PrometheusThreshold, Op: "<"}, false},
+		{"valid empty", &PrometheusChecker{Query: "ALERTS", Mode: PrometheusEmpty}, false},
+		{"missing query", &PrometheusChecker{Mode: PrometheusEmpty}, true},
+		{"missing operator", &PrometheusChecker{Query: "up", Mode: PrometheusThreshold}, true},
+		{"unsupported mode", &PrometheusChecker{Query: "up", Mode: "absent"}, true},
+		{"valid no data", &PrometheusChecker{Query: "up", Mode: PrometheusThreshold, Op: "<", NoData: PrometheusNoDataIgnore}, false},
+		{"unsupported no data", &PrometheusChecker{Query: "up", Mode: PrometheusThreshold, Op: "<", NoData: "skip"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.checker.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
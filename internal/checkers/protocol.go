@@ -0,0 +1,116 @@
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// maxProtocolSize limits how much a line based protocol checker reads from
+// a single connection.
+const maxProtocolSize = 1 << 16
+
+// TLSMode selects how the SMTP, IMAP and LDAP checkers use TLS.
+type TLSMode string
+
+const (
+	// TLSNone talks plaintext.
+	TLSNone TLSMode = ""
+	// TLSStartTLS upgrades the connection with the STARTTLS command of the
+	// protocol and requires the server to offer it.
+	TLSStartTLS TLSMode = "starttls"
+	// TLSImplicit starts with a TLS handshake, as on SMTPS, IMAPS and LDAPS
+	// ports.
+	TLSImplicit TLSMode = "tls"
+)
+
+// ParseTLSMode parses "none", "starttls" or "tls".
+func ParseTLSMode(s string) (TLSMode, error) {
+	switch s {
+	case "", "none":
+		return TLSNone, nil
+	case "starttls":
+		return TLSStartTLS, nil
+	case "tls":
+		return TLSImplicit, nil
+	default:
+		return "", fmt.Errorf("unsupported TLS mode %q", s)
+	}
+}
+
+// protocolTLSConfig builds the TLS config of a protocol checker. The server
+// name defaults to the host of address.
+func protocolTLSConfig(address string, skipVerify bool, serverName string, minVersion uint16, files *TLSFiles) (*tls.Config, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverName == "" {
+		serverName = host
+	}
+
+	return newTLSConfig(skipVerify, serverName, minVersion, files)
+}
+
+// dialProtocol connects to address, performing the TLS handshake first in
+// implicit TLS mode.
+func dialProtocol(ctx context.Context, address string, mode TLSMode, cfg *tls.Config) (net.Conn, error) {
+	conn, err := dialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode != TLSImplicit {
+		return conn, nil
+	}
+
+	tlsConn, err := startTLS(ctx, conn, cfg)
+	if err != nil {
+		conn.Close() //nolint:errcheck
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+// startTLS performs a client TLS handshake on conn.
+func startTLS(ctx context.Context, conn net.Conn, cfg *tls.Config) (net.Conn, error) {
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, fmt.Errorf("TLS handshake: %w", err)
+	}
+
+	return tlsConn, nil
+}
+
+// newTextConn wraps conn for a line based protocol, limiting how much is
+// read from it.
+func newTextConn(conn net.Conn) (*textproto.Reader, *textproto.Writer) {
+	r := textproto.NewReader(bufio.NewReader(io.LimitReader(conn, maxProtocolSize)))
+	w := textproto.NewWriter(bufio.NewWriter(conn))
+
+	return r, w
+}
+
+// protocolErrorClass tells unexpected replies of the server from connection
+// errors.
+func protocolErrorClass(err error) gslb.ErrorClass {
+	var (
+		replyErr    *textproto.Error
+		protocolErr textproto.ProtocolError
+	)
+
+	if errors.As(err, &replyErr) || errors.As(err, &protocolErr) {
+		return gslb.ErrorResponse
+	}
+
+	return errorClass(err)
+}
@@ -0,0 +1,202 @@
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// maxRedisReplySize limits the size of bulk replies such as INFO.
+const maxRedisReplySize = 1 << 16
+
+// RedisChecker sends PING to a Redis server, optionally authenticating first
+// and verifying that the server is a master.
+type RedisChecker struct {
+	Address string
+
+	// User is only sent if set, otherwise the default user is used.
+	User string
+
+	// PasswordFile is read on every check, so rotated passwords are picked
+	// up without a restart. No AUTH is sent if empty.
+	PasswordFile string
+
+	// RequireMaster reports replicas as unhealthy.
+	RequireMaster bool
+
+	Retry RetryPolicy
+}
+
+func NewRedisChecker(address string) *RedisChecker {
+	return &RedisChecker{
+		Address: address,
+		Retry:   SingleAttemptPolicy(5 * time.Second),
+	}
+}
+
+// Check implements gslb.HealthChecker.
+func (c *RedisChecker) Check(ctx context.Context) (gslb.Result, error) {
+	// Validate address before attempting to connect
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return gslb.Result{}, err
+	}
+
+	password, err := readSecretFile(c.PasswordFile)
+	if err != nil {
+		return gslb.Result{}, err
+	}
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		return c.check(ctx, password)
+	})
+}
+
+func (c *RedisChecker) check(ctx context.Context, password string) gslb.Result {
+	start := time.Now()
+
+	conn, err := dialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return failure(errorClass(err), err.Error(), time.Since(start))
+	}
+	defer conn.Close() //nolint:errcheck
+
+	r := bufio.NewReader(conn)
+
+	if password != "" {
+		args := []string{"AUTH", password}
+		if c.User != "" {
+			args = []string{"AUTH", c.User, password}
+		}
+
+		if _, err := redisCommand(conn, r, args...); err != nil {
+			return failure(redisErrorClass(err), fmt.Sprintf("AUTH: %s", err), time.Since(start))
+		}
+	}
+
+	pong, err := redisCommand(conn, r, "PING")
+	if err != nil {
+		return failure(redisErrorClass(err), fmt.Sprintf("PING: %s", err), time.Since(start))
+	}
+	if pong != "PONG" {
+		return failure(gslb.ErrorResponse, fmt.Sprintf("unexpected PING reply %q", pong), time.Since(start))
+	}
+
+	status := "PONG"
+	details := map[string]string{}
+
+	if c.RequireMaster {
+		info, err := redisCommand(conn, r, "INFO", "replication")
+		if err != nil {
+			return failure(redisErrorClass(err), fmt.Sprintf("INFO: %s", err), time.Since(start))
+		}
+
+		role := redisInfoField(info, "role")
+		details["role"] = role
+
+		if role != "master" {
+			res := failure(gslb.ErrorResponse, fmt.Sprintf("role is %q, not master", role), time.Since(start))
+			res.Details = details
+
+			return res
+		}
+
+		status += " from master"
+	}
+
+	latency := time.Since(start)
+
+	return gslb.Result{
+		State:   gslb.StateHealthy,
+		Status:  fmt.Sprintf("%s in %s", status, formatLatency(latency)),
+		Latency: latency,
+		Details: details,
+	}
+}
+
+// redisError is an error reply of the server.
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+func redisErrorClass(err error) gslb.ErrorClass {
+	var replyErr redisError
+	if errors.As(err, &replyErr) {
+		return gslb.ErrorResponse
+	}
+
+	return errorClass(err)
+}
+
+// redisCommand sends a command as RESP array and reads a simple string or
+// bulk string reply.
+func redisCommand(w io.Writer, r *bufio.Reader, args ...string) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return "", err
+	}
+
+	return redisReply(r)
+}
+
+func redisReply(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return "", fmt.Errorf("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return "", redisError(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n > maxRedisReplySize {
+			return "", fmt.Errorf("invalid bulk reply %q", line)
+		}
+		if n < 0 {
+			return "", nil
+		}
+
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+
+		return string(buf[:n]), nil
+	default:
+		return "", fmt.Errorf("unexpected reply %q", line)
+	}
+}
+
+// redisInfoField returns the value of a field of an INFO reply.
+func redisInfoField(info, name string) string {
+	for _, line := range strings.Split(info, "\n") {
+		if key, val, ok := strings.Cut(strings.TrimSpace(line), ":"); ok && key == name {
+			return val
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,185 @@
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// startRedisServer starts a Redis stand-in requiring password if set and
+// reporting role in INFO replication.
+func startRedisServer(t *testing.T, password, role string) string {
+	t.Helper()
+
+	return startTCPServer(t, func(c net.Conn) {
+		r := bufio.NewReader(c)
+		authenticated := password == ""
+
+		for {
+			args, err := readRESPArray(r)
+			if err != nil {
+				return
+			}
+
+			switch strings.ToUpper(args[0]) {
+			case "AUTH":
+				if args[len(args)-1] != password {
+					fmt.Fprint(c, "-WRONGPASS invalid username-password pair or user is disabled.\r\n")
+					continue
+				}
+				authenticated = true
+				fmt.Fprint(c, "+OK\r\n")
+			case "PING":
+				if !authenticated {
+					fmt.Fprint(c, "-NOAUTH Authentication required.\r\n")
+					continue
+				}
+				fmt.Fprint(c, "+PONG\r\n")
+			case "INFO":
+				info := "# Replication\r\nrole:" + role + "\r\nconnected_slaves:0\r\n"
+				fmt.Fprintf(c, "$%d\r\n%s\r\n", len(info), info)
+			default:
+				fmt.Fprintf(c, "-ERR unknown command '%s'\r\n", args[0])
+			}
+		}
+	})
+}
+
+// readRESPArray reads a command sent as RESP array of bulk strings.
+func readRESPArray(r *bufio.Reader) ([]string, error) {
+	var n int
+	if _, err := fmt.Fscanf(r, "*%d\r\n", &n); err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		var size int
+		if _, err := fmt.Fscanf(r, "$%d\r\n", &size); err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+
+	return args, nil
+}
+
+func writeSecret(t *testing.T, secret string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte(secret+"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+
+	return path
+}
+
+func TestRedisChecker_Check(t *testing.T) {
+	tests := []struct {
+		name          string
+		password      string
+		role          string
+		clientSecret  string
+		requireMaster bool
+		wantState     gslb.State
+		wantStatus    string
+	}{
+		{
+			name:       "ping",
+			role:       "master",
+			wantState:  gslb.StateHealthy,
+			wantStatus: "PONG",
+		},
+		{
+			name:         "authenticated",
+			password:     "s3cret",
+			role:         "master",
+			clientSecret: "s3cret",
+			wantState:    gslb.StateHealthy,
+			wantStatus:   "PONG",
+		},
+		{
+			name:         "wrong password",
+			password:     "s3cret",
+			role:         "master",
+			clientSecret: "guess",
+			wantState:    gslb.StateUnhealthy,
+			wantStatus:   "WRONGPASS",
+		},
+		{
+			name:       "missing password",
+			password:   "s3cret",
+			role:       "master",
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: "NOAUTH",
+		},
+		{
+			name:          "master required",
+			role:          "master",
+			requireMaster: true,
+			wantState:     gslb.StateHealthy,
+			wantStatus:    "PONG from master",
+		},
+		{
+			name:          "replica rejected",
+			role:          "slave",
+			requireMaster: true,
+			wantState:     gslb.StateUnhealthy,
+			wantStatus:    `role is "slave", not master`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewRedisChecker(startRedisServer(t, tt.password, tt.role))
+			checker.RequireMaster = tt.requireMaster
+			if tt.clientSecret != "" {
+				checker.PasswordFile = writeSecret(t, tt.clientSecret)
+			}
+
+			res, err := checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState {
+				t.Errorf("Check() state = %v, want %v (status %q)", res.State, tt.wantState, res.Status)
+			}
+			if !strings.Contains(res.Status, tt.wantStatus) {
+				t.Errorf("Check() status = %q, want %q", res.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRedisChecker_Check_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker *RedisChecker
+	}{
+		{"invalid address", NewRedisChecker("localhost")},
+		{"missing password file", &RedisChecker{Address: "localhost:6379", PasswordFile: "/nonexistent/password"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.checker.Check(context.Background()); err == nil {
+				t.Errorf("Check() expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// failure builds an unhealthy result.
+func failure(class gslb.ErrorClass, status string, latency time.Duration) gslb.Result {
+	return gslb.Result{
+		State:   gslb.StateUnhealthy,
+		Class:   class,
+		Status:  status,
+		Latency: latency,
+	}
+}
+
+// errorClass classifies errors of connecting to and talking to a target.
+func errorClass(err error) gslb.ErrorClass {
+	var (
+		netErr      net.Error
+		recordErr   tls.RecordHeaderError
+		alertErr    tls.AlertError
+		verifyErr   *tls.CertificateVerificationError
+		unknownErr  x509.UnknownAuthorityError
+		hostnameErr x509.HostnameError
+		invalidErr  x509.CertificateInvalidError
+	)
+
+	switch {
+	case err == nil:
+		return gslb.ErrorNone
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return gslb.ErrorTimeout
+	case errors.As(err, &recordErr), errors.As(err, &alertErr), errors.As(err, &verifyErr),
+		errors.As(err, &unknownErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return gslb.ErrorTLS
+	default:
+		return gslb.ErrorConnection
+	}
+}
@@ -0,0 +1,211 @@
+package checkers
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+type Backoff string
+
+const (
+	BackoffFixed       Backoff = "fixed"
+	BackoffExponential Backoff = "exponential"
+)
+
+// RetryPolicy decides how often and how fast a check is attempted before the
+// target is considered unhealthy.
+type RetryPolicy struct {
+	// Attempts is the total number of attempts, at least one is made.
+	Attempts int
+
+	// Timeout limits every single attempt, zero means no limit besides the
+	// context passed to the check.
+	Timeout time.Duration
+
+	// Delay is the wait before the first retry. With exponential backoff it
+	// doubles for every further retry, up to MaxDelay if set.
+	Delay    time.Duration
+	Backoff  Backoff
+	MaxDelay time.Duration
+
+	// Jitter randomizes every delay by up to the given fraction in both
+	// directions, e.g. 0.2 turns 10s into 8s to 12s.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is used by checkers unless configured otherwise: three
+// attempts of five seconds each, ten seconds apart.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Attempts: 3,
+		Timeout:  5 * time.Second,
+		Delay:    10 * time.Second,
+		Backoff:  BackoffFixed,
+	}
+}
+
+// SingleAttemptPolicy makes exactly one attempt limited by timeout.
+func SingleAttemptPolicy(timeout time.Duration) RetryPolicy {
+	return RetryPolicy{
+		Attempts: 1,
+		Timeout:  timeout,
+		Backoff:  BackoffFixed,
+	}
+}
+
+// Validate reports configuration errors of the policy.
+func (p RetryPolicy) Validate() error {
+	switch {
+	case p.Attempts < 0:
+		return fmt.Errorf("retry attempts must not be negative")
+	case p.Timeout < 0 || p.Delay < 0 || p.MaxDelay < 0:
+		return fmt.Errorf("retry durations must not be negative")
+	case p.Jitter < 0 || p.Jitter > 1:
+		return fmt.Errorf("retry jitter must be between 0 and 1")
+	case p.Backoff != "" && p.Backoff != BackoffFixed && p.Backoff != BackoffExponential:
+		return fmt.Errorf("unsupported retry backoff %q", p.Backoff)
+	}
+
+	return nil
+}
+
+// MaxDuration returns the worst case duration of a check, with every attempt
+// timing out and every delay at its maximum. It is zero if the attempts are
+// not limited by a timeout.
+func (p RetryPolicy) MaxDuration() time.Duration {
+	if p.Timeout == 0 {
+		return 0
+	}
+
+	attempts := max(p.Attempts, 1)
+	d := time.Duration(attempts) * p.Timeout
+
+	for i := 1; i < attempts; i++ {
+		delay := p.baseDelay(i)
+		d += delay + time.Duration(float64(delay)*p.Jitter)
+	}
+
+	return d
+}
+
+// Do calls attempt until it reports healthy or all attempts are used up and
+// returns the result of the last attempt. Every attempt gets its own context
+// limited by the policy timeout. An error is only returned if ctx is done,
+// as a cancelled check says nothing about the target health.
+func (p RetryPolicy) Do(ctx context.Context, attempt func(ctx context.Context) (bool, string)) (bool, string, error) {
+	res, err := p.Run(ctx, func(ctx context.Context) gslb.Result {
+		return gslb.ResultOf(attempt(ctx))
+	})
+	if err != nil {
+		return false, "", err
+	}
+
+	return res.Healthy(), res.Status, nil
+}
+
+// Run is like Do for attempts reporting a gslb.Result. Degraded results are
+// not retried, and the number of attempts made is added to the result.
+func (p RetryPolicy) Run(ctx context.Context, attempt func(ctx context.Context) gslb.Result) (gslb.Result, error) {
+	attempts := max(p.Attempts, 1)
+
+	var last gslb.Result
+
+	for i := 1; i <= attempts; i++ {
+		last = p.try(ctx, attempt)
+		last.Attempts = i
+
+		if err := contextDone(ctx); err != nil {
+			return gslb.Result{}, err
+		}
+
+		if last.Healthy() {
+			return last, nil
+		}
+
+		// If this is not the last attempt, wait before retrying
+		if i < attempts {
+			if err := sleepContext(ctx, p.delay(i)); err != nil {
+				return gslb.Result{}, err
+			}
+		}
+	}
+
+	return last, nil
+}
+
+func (p RetryPolicy) try(ctx context.Context, attempt func(ctx context.Context) gslb.Result) gslb.Result {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	return attempt(ctx)
+}
+
+// delay returns the wait after the given attempt.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.baseDelay(attempt)
+
+	if p.Jitter > 0 && d > 0 {
+		// Spread evenly over [d - d*Jitter, d + d*Jitter]
+		spread := float64(d) * p.Jitter
+		d += time.Duration((rand.Float64()*2 - 1) * spread)
+	}
+
+	return d
+}
+
+// baseDelay returns the wait after the given attempt without jitter.
+func (p RetryPolicy) baseDelay(attempt int) time.Duration {
+	d := p.Delay
+
+	if p.Backoff == BackoffExponential {
+		for i := 1; i < attempt; i++ {
+			d *= 2
+
+			if p.MaxDelay > 0 && d >= p.MaxDelay {
+				break
+			}
+		}
+	}
+
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	return d
+}
+
+// contextDone returns the error of ctx, also if its deadline has passed but
+// the context is not cancelled yet. Connection deadlines derived from ctx can
+// fire first, so a failed attempt may be due to the caller running out of
+// time.
+func contextDone(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if dl, ok := ctx.Deadline(); ok && !time.Now().Before(dl) {
+		return context.DeadlineExceeded
+	}
+
+	return nil
+}
+
+// sleepContext waits for d or until ctx is done, whichever happens first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
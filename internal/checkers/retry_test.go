@@ -0,0 +1,233 @@
+package checkers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Do(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       RetryPolicy
+		healthyAfter int
+		wantHealthy  bool
+		wantAttempts int
+	}{
+		{
+			name:         "healthy on first attempt",
+			policy:       RetryPolicy{Attempts: 3},
+			healthyAfter: 1,
+			wantHealthy:  true,
+			wantAttempts: 1,
+		},
+		{
+			name:         "healthy on last attempt",
+			policy:       RetryPolicy{Attempts: 3, Delay: time.Millisecond},
+			healthyAfter: 3,
+			wantHealthy:  true,
+			wantAttempts: 3,
+		},
+		{
+			name:         "unhealthy after all attempts",
+			policy:       RetryPolicy{Attempts: 2, Delay: time.Millisecond},
+			healthyAfter: 5,
+			wantHealthy:  false,
+			wantAttempts: 2,
+		},
+		{
+			name:         "zero attempts still tries once",
+			policy:       RetryPolicy{},
+			healthyAfter: 5,
+			wantHealthy:  false,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+
+			healthy, status, err := tt.policy.Do(context.Background(), func(ctx context.Context) (bool, string) {
+				attempts++
+				if attempts >= tt.healthyAfter {
+					return true, "ok"
+				}
+				return false, "failed"
+			})
+			if err != nil {
+				t.Fatalf("Do() unexpected error: %v", err)
+			}
+
+			if healthy != tt.wantHealthy {
+				t.Errorf("Do() healthy = %v, want %v", healthy, tt.wantHealthy)
+			}
+
+			if (status == "ok") != tt.wantHealthy {
+				t.Errorf("Do() status = %q does not belong to the last attempt", status)
+			}
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Do_AttemptTimeout(t *testing.T) {
+	policy := RetryPolicy{Attempts: 2, Timeout: 20 * time.Millisecond}
+
+	healthy, status, err := policy.Do(context.Background(), func(ctx context.Context) (bool, string) {
+		<-ctx.Done()
+		return false, ctx.Err().Error()
+	})
+	if err != nil {
+		t.Fatalf("Do() unexpected error: %v", err)
+	}
+
+	if healthy {
+		t.Errorf("Do() healthy = true, want false")
+	}
+
+	if status != context.DeadlineExceeded.Error() {
+		t.Errorf("Do() status = %q, want %q", status, context.DeadlineExceeded.Error())
+	}
+}
+
+func TestRetryPolicy_Do_ContextCancelled(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, Delay: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := policy.Do(ctx, func(ctx context.Context) (bool, string) {
+		return false, "failed"
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// expiredContext has passed its deadline, but is not cancelled yet, like a
+// context whose timer has not fired.
+type expiredContext struct {
+	context.Context
+}
+
+func (expiredContext) Deadline() (time.Time, bool) {
+	return time.Now().Add(-time.Millisecond), true
+}
+
+func TestRetryPolicy_Do_DeadlinePassed(t *testing.T) {
+	policy := RetryPolicy{Attempts: 1}
+
+	// A connection deadline fired before the context was cancelled
+	_, _, err := policy.Do(expiredContext{context.Background()}, func(ctx context.Context) (bool, string) {
+		return false, "i/o timeout"
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{
+			name:    "fixed",
+			policy:  RetryPolicy{Delay: time.Second, Backoff: BackoffFixed},
+			attempt: 3,
+			want:    time.Second,
+		},
+		{
+			name:    "exponential first retry",
+			policy:  RetryPolicy{Delay: time.Second, Backoff: BackoffExponential},
+			attempt: 1,
+			want:    time.Second,
+		},
+		{
+			name:    "exponential third retry",
+			policy:  RetryPolicy{Delay: time.Second, Backoff: BackoffExponential},
+			attempt: 3,
+			want:    4 * time.Second,
+		},
+		{
+			name:    "exponential capped",
+			policy:  RetryPolicy{Delay: time.Second, Backoff: BackoffExponential, MaxDelay: 3 * time.Second},
+			attempt: 10,
+			want:    3 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.delay(tt.attempt); got != tt.want {
+				t.Errorf("delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_DelayJitter(t *testing.T) {
+	policy := RetryPolicy{Delay: 10 * time.Second, Jitter: 0.2}
+
+	for range 100 {
+		d := policy.delay(1)
+		if d < 8*time.Second || d > 12*time.Second {
+			t.Fatalf("delay(1) = %v, want between 8s and 12s", d)
+		}
+	}
+}
+
+func TestRetryPolicy_MaxDuration(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		want   time.Duration
+	}{
+		{name: "default", policy: DefaultRetryPolicy(), want: 35 * time.Second},
+		{name: "single attempt", policy: SingleAttemptPolicy(5 * time.Second), want: 5 * time.Second},
+		{
+			name:   "exponential with jitter",
+			policy: RetryPolicy{Attempts: 4, Timeout: time.Second, Delay: time.Second, Backoff: BackoffExponential, MaxDelay: 3 * time.Second, Jitter: 0.5},
+			want:   4*time.Second + 1500*time.Millisecond + 3*time.Second + 4500*time.Millisecond,
+		},
+		{name: "no timeout", policy: RetryPolicy{Attempts: 3, Delay: time.Second}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.MaxDuration(); got != tt.want {
+				t.Errorf("MaxDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		wantErr bool
+	}{
+		{name: "default", policy: DefaultRetryPolicy()},
+		{name: "zero value", policy: RetryPolicy{}},
+		{name: "negative attempts", policy: RetryPolicy{Attempts: -1}, wantErr: true},
+		{name: "negative delay", policy: RetryPolicy{Delay: -time.Second}, wantErr: true},
+		{name: "jitter too large", policy: RetryPolicy{Jitter: 1.5}, wantErr: true},
+		{name: "unknown backoff", policy: RetryPolicy{Backoff: "linear"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
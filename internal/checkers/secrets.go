@@ -0,0 +1,23 @@
+package checkers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// readSecretFile returns the content of a password file without the trailing
+// newline. The file is read on every check, so rotated secrets are picked up
+// without a restart. An empty path returns an empty secret.
+func readSecretFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading secret file: %w", err)
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
@@ -0,0 +1,145 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// SMTPChecker reads the banner of an SMTP server and sends EHLO, optionally
+// upgrading the connection with STARTTLS first.
+type SMTPChecker struct {
+	Address string
+
+	// HeloName is sent with EHLO, "localhost" if empty.
+	HeloName string
+
+	TLSMode       TLSMode
+	SkipTLSVerify bool
+	ServerName    string
+	TLSFiles      *TLSFiles
+	MinTLSVersion uint16
+
+	Retry RetryPolicy
+}
+
+func NewSMTPChecker(address string) *SMTPChecker {
+	return &SMTPChecker{
+		Address: address,
+		Retry:   SingleAttemptPolicy(5 * time.Second),
+	}
+}
+
+// Check implements gslb.HealthChecker.
+func (c *SMTPChecker) Check(ctx context.Context) (gslb.Result, error) {
+	// Validate address before attempting to connect
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return gslb.Result{}, err
+	}
+
+	cfg, err := protocolTLSConfig(c.Address, c.SkipTLSVerify, c.ServerName, c.MinTLSVersion, c.TLSFiles)
+	if err != nil {
+		return gslb.Result{}, err
+	}
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		return c.check(ctx, cfg)
+	})
+}
+
+func (c *SMTPChecker) check(ctx context.Context, cfg *tls.Config) gslb.Result {
+	start := time.Now()
+
+	conn, err := dialProtocol(ctx, c.Address, c.TLSMode, cfg)
+	if err != nil {
+		return failure(errorClass(err), err.Error(), time.Since(start))
+	}
+	defer func() { conn.Close() }() //nolint:errcheck
+
+	r, w := newTextConn(conn)
+
+	_, banner, err := r.ReadResponse(220)
+	if err != nil {
+		return failure(protocolErrorClass(err), fmt.Sprintf("banner: %s", err), time.Since(start))
+	}
+
+	ext, err := smtpCommand(r, w, 250, "EHLO %s", c.heloName())
+	if err != nil {
+		return failure(protocolErrorClass(err), fmt.Sprintf("EHLO: %s", err), time.Since(start))
+	}
+
+	status := "EHLO accepted"
+
+	if c.TLSMode == TLSStartTLS {
+		if !smtpHasExtension(ext, "STARTTLS") {
+			return failure(gslb.ErrorTLS, "STARTTLS not offered", time.Since(start))
+		}
+
+		if _, err := smtpCommand(r, w, 220, "STARTTLS"); err != nil {
+			return failure(protocolErrorClass(err), fmt.Sprintf("STARTTLS: %s", err), time.Since(start))
+		}
+
+		tlsConn, err := startTLS(ctx, conn, cfg)
+		if err != nil {
+			return failure(errorClass(err), err.Error(), time.Since(start))
+		}
+
+		conn = tlsConn
+		r, w = newTextConn(conn)
+
+		if _, err := smtpCommand(r, w, 250, "EHLO %s", c.heloName()); err != nil {
+			return failure(protocolErrorClass(err), fmt.Sprintf("EHLO after STARTTLS: %s", err), time.Since(start))
+		}
+
+		status += " after STARTTLS"
+	}
+
+	latency := time.Since(start)
+
+	// Say goodbye politely, the result does not depend on it
+	w.PrintfLine("QUIT") //nolint:errcheck
+
+	banner, _, _ = strings.Cut(banner, "\n")
+
+	return gslb.Result{
+		State:   gslb.StateHealthy,
+		Status:  fmt.Sprintf("%s in %s: %s", status, formatLatency(latency), banner),
+		Latency: latency,
+		Details: map[string]string{"banner": banner},
+	}
+}
+
+func (c *SMTPChecker) heloName() string {
+	if c.HeloName == "" {
+		return "localhost"
+	}
+
+	return c.HeloName
+}
+
+// smtpCommand sends a command and reads its reply, which must have the
+// expected code.
+func smtpCommand(r *textproto.Reader, w *textproto.Writer, expect int, format string, args ...any) (string, error) {
+	if err := w.PrintfLine(format, args...); err != nil {
+		return "", err
+	}
+
+	_, msg, err := r.ReadResponse(expect)
+
+	return msg, err
+}
+
+// smtpHasExtension reports whether an EHLO reply lists the extension.
+func smtpHasExtension(ehlo, name string) bool {
+	return slices.ContainsFunc(strings.Split(ehlo, "\n"), func(line string) bool {
+		keyword, _, _ := strings.Cut(line, " ")
+		return strings.EqualFold(keyword, name)
+	})
+}
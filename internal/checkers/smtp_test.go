@@ -0,0 +1,146 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// serveSMTP answers like a mail server offering STARTTLS if cert is set.
+func serveSMTP(cert *tls.Certificate, banner string) func(net.Conn) {
+	return func(conn net.Conn) {
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("%s", banner) //nolint:errcheck
+
+		if !strings.HasPrefix(banner, "220") {
+			return
+		}
+
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+
+			switch cmd, _, _ := strings.Cut(line, " "); cmd {
+			case "EHLO":
+				if cert != nil {
+					tp.PrintfLine("250-mx.example.com\r\n250-PIPELINING\r\n250 STARTTLS") //nolint:errcheck
+				} else {
+					tp.PrintfLine("250-mx.example.com\r\n250 PIPELINING") //nolint:errcheck
+				}
+			case "STARTTLS":
+				tp.PrintfLine("220 Ready to start TLS") //nolint:errcheck
+
+				tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{*cert}})
+				if err := tlsConn.Handshake(); err != nil {
+					return
+				}
+
+				conn = tlsConn
+				tp = textproto.NewConn(tlsConn)
+			case "QUIT":
+				tp.PrintfLine("221 Bye") //nolint:errcheck
+				return
+			default:
+				tp.PrintfLine("502 Command not implemented") //nolint:errcheck
+			}
+		}
+	}
+}
+
+func TestSMTPChecker_Check(t *testing.T) {
+	cert := newTestCertificate(t, time.Now().Add(time.Hour))
+	caFile, _ := writeTestCertificate(t, t.TempDir(), cert)
+
+	files, err := NewTLSFiles(caFile, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		handler    func(net.Conn)
+		mode       TLSMode
+		wantState  gslb.State
+		wantClass  gslb.ErrorClass
+		wantStatus string
+	}{
+		{
+			name:       "plaintext",
+			handler:    serveSMTP(nil, "220 mx.example.com ESMTP"),
+			wantState:  gslb.StateHealthy,
+			wantStatus: "EHLO accepted in",
+		},
+		{
+			name:       "starttls",
+			handler:    serveSMTP(&cert, "220 mx.example.com ESMTP"),
+			mode:       TLSStartTLS,
+			wantState:  gslb.StateHealthy,
+			wantStatus: "EHLO accepted after STARTTLS in",
+		},
+		{
+			name:       "starttls not offered",
+			handler:    serveSMTP(nil, "220 mx.example.com ESMTP"),
+			mode:       TLSStartTLS,
+			wantState:  gslb.StateUnhealthy,
+			wantClass:  gslb.ErrorTLS,
+			wantStatus: "STARTTLS not offered",
+		},
+		{
+			name:       "service not available",
+			handler:    serveSMTP(nil, "421 mx.example.com Service not available"),
+			wantState:  gslb.StateUnhealthy,
+			wantClass:  gslb.ErrorResponse,
+			wantStatus: `banner: 421 "mx.example.com Service not available"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewSMTPChecker(startTCPServer(t, tt.handler))
+			c.TLSMode = tt.mode
+			c.TLSFiles = files
+
+			res, err := c.Check(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState || res.Class != tt.wantClass {
+				t.Errorf("expected %s (%q), got %s (%q): %s", tt.wantState, tt.wantClass, res.State, res.Class, res.Status)
+			}
+			if !strings.HasPrefix(res.Status, tt.wantStatus) {
+				t.Errorf("expected status starting with %q, got %q", tt.wantStatus, res.Status)
+			}
+		})
+	}
+}
+
+func TestSMTPChecker_Check_UntrustedCertificate(t *testing.T) {
+	cert := newTestCertificate(t, time.Now().Add(time.Hour))
+
+	c := NewSMTPChecker(startTCPServer(t, serveSMTP(&cert, "220 mx.example.com ESMTP")))
+	c.TLSMode = TLSStartTLS
+
+	res, err := c.Check(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.State != gslb.StateUnhealthy || res.Class != gslb.ErrorTLS {
+		t.Errorf("expected TLS failure, got %s (%q): %s", res.State, res.Class, res.Status)
+	}
+
+	c.SkipTLSVerify = true
+
+	if res, _ := c.Check(context.Background()); res.State != gslb.StateHealthy {
+		t.Errorf("expected healthy without verification, got %s: %s", res.State, res.Status)
+	}
+}
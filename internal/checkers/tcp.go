@@ -0,0 +1,142 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// maxBannerSize limits how much data is read while waiting for the expected
+// banner, so a chatty service cannot make the checker buffer endlessly.
+const maxBannerSize = 4096
+
+type TCPChecker struct {
+	Address string
+	Send    string
+	Expect  string
+	Retry   RetryPolicy
+}
+
+func NewTCPChecker(address string, timeout time.Duration) *TCPChecker {
+	return &TCPChecker{
+		Address: address,
+		Retry:   SingleAttemptPolicy(timeout),
+	}
+}
+
+// Check implements gslb.HealthChecker.
+func (c *TCPChecker) Check(ctx context.Context) (gslb.Result, error) {
+	// Validate address before attempting to connect
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return gslb.Result{}, err
+	}
+
+	return c.Retry.Run(ctx, c.check)
+}
+
+func (c *TCPChecker) check(ctx context.Context) gslb.Result {
+	start := time.Now()
+
+	// The attempt timeout covers the whole exchange, not only the handshake
+	conn, err := dialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		// Connection errors mean service is unhealthy, not an internal error
+		return failure(errorClass(err), err.Error(), time.Since(start))
+	}
+	defer conn.Close() //nolint:errcheck
+
+	if c.Send != "" {
+		if _, err := io.WriteString(conn, c.Send); err != nil {
+			return failure(errorClass(err), fmt.Sprintf("sending data: %s", err), time.Since(start))
+		}
+	}
+
+	status := "connected"
+	if c.Expect != "" {
+		if err := readExpect(conn, c.Expect); err != nil {
+			return failure(expectErrorClass(err), err.Error(), time.Since(start))
+		}
+
+		status = "expected response received"
+	}
+
+	return gslb.Result{State: gslb.StateHealthy, Status: status, Latency: time.Since(start)}
+}
+
+// dialContext connects to address and ties the connection deadline to ctx,
+// so reads and writes are interrupted when ctx is cancelled or expires.
+func dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close() //nolint:errcheck
+			return nil, err
+		}
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now()) //nolint:errcheck
+	})
+
+	return &ctxConn{Conn: conn, stop: stop}, nil
+}
+
+// ctxConn releases the context watch of dialContext when closed.
+type ctxConn struct {
+	net.Conn
+	stop func() bool
+}
+
+func (c *ctxConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
+
+// readExpect reads from r until the expected string shows up, the connection
+// is closed or the deadline expires.
+func readExpect(r io.Reader, expect string) error {
+	var received []byte
+	buf := make([]byte, 512)
+
+	for len(received) < maxBannerSize {
+		n, err := r.Read(buf)
+		received = append(received, buf[:n]...)
+
+		if bytes.Contains(received, []byte(expect)) {
+			return nil
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("connection closed before %q was received", expect)
+			}
+
+			return fmt.Errorf("waiting for %q: %w", expect, err)
+		}
+	}
+
+	return fmt.Errorf("%q not found in first %d bytes", expect, maxBannerSize)
+}
+
+// expectErrorClass classifies errors of readExpect, a missing string is a
+// response error.
+func expectErrorClass(err error) gslb.ErrorClass {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return errorClass(err)
+	}
+
+	return gslb.ErrorResponse
+}
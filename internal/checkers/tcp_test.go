@@ -0,0 +1,168 @@
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// startTCPServer starts a listener that hands every accepted connection to
+// handler and returns its address.
+func startTCPServer(t *testing.T, handler func(net.Conn)) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() }) //nolint:errcheck
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close() //nolint:errcheck
+				handler(conn)
+			}()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestTCPChecker_Check(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     func(net.Conn)
+		send        string
+		expect      string
+		wantHealthy bool
+	}{
+		{
+			name:        "connect only",
+			handler:     func(net.Conn) {},
+			wantHealthy: true,
+		},
+		{
+			name: "banner matches",
+			handler: func(c net.Conn) {
+				c.Write([]byte("220 mail.example.com ESMTP ready\r\n")) //nolint:errcheck
+			},
+			expect:      "220 ",
+			wantHealthy: true,
+		},
+		{
+			name: "banner does not match",
+			handler: func(c net.Conn) {
+				c.Write([]byte("421 service not available\r\n")) //nolint:errcheck
+			},
+			expect:      "220 ",
+			wantHealthy: false,
+		},
+		{
+			name: "send and expect",
+			handler: func(c net.Conn) {
+				line, _ := bufio.NewReader(c).ReadString('\n')
+				if strings.TrimSpace(line) == "PING" {
+					c.Write([]byte("+PONG\r\n")) //nolint:errcheck
+				}
+			},
+			send:        "PING\r\n",
+			expect:      "+PONG",
+			wantHealthy: true,
+		},
+		{
+			name: "no response before timeout",
+			handler: func(c net.Conn) {
+				time.Sleep(500 * time.Millisecond)
+			},
+			expect:      "220 ",
+			wantHealthy: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := startTCPServer(t, tt.handler)
+
+			checker := NewTCPChecker(addr, 200*time.Millisecond)
+			checker.Send = tt.send
+			checker.Expect = tt.expect
+
+			res, err := checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+
+			if res.Healthy() != tt.wantHealthy {
+				t.Errorf("Check() healthy = %v, want %v (status %q)", res.Healthy(), tt.wantHealthy, res.Status)
+			}
+
+			if res.Status == "" {
+				t.Errorf("Check() expected non-empty status message")
+			}
+		})
+	}
+}
+
+func TestTCPChecker_Check_ConnectionRefused(t *testing.T) {
+	// Grab a free port and close the listener again so nothing is listening
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close() //nolint:errcheck
+
+	checker := NewTCPChecker(addr, time.Second)
+	res, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if res.Healthy() {
+		t.Errorf("Check() healthy = true, want false")
+	}
+
+	if res.Status == "" {
+		t.Errorf("Check() expected non-empty status message for connection error")
+	}
+
+	if res.Class != gslb.ErrorConnection {
+		t.Errorf("Check() class = %q, want %q", res.Class, gslb.ErrorConnection)
+	}
+}
+
+func TestTCPChecker_Check_InvalidAddress(t *testing.T) {
+	checker := NewTCPChecker("missing-port", time.Second)
+
+	if _, err := checker.Check(context.Background()); err == nil {
+		t.Errorf("Check() expected error for invalid address, got nil")
+	}
+}
+
+func TestTCPChecker_Check_ContextCancelled(t *testing.T) {
+	addr := startTCPServer(t, func(c net.Conn) {
+		time.Sleep(time.Second)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	checker := NewTCPChecker(addr, 5*time.Second)
+	checker.Expect = "220 "
+
+	if _, err := checker.Check(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Check() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
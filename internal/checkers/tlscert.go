@@ -0,0 +1,179 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// TLSChecker performs a TLS handshake and inspects the certificate served,
+// so an expired or wrong certificate is noticed before clients break.
+type TLSChecker struct {
+	Address string
+
+	// ServerName is sent as SNI, Hostname must be covered by the
+	// certificate. Hostname is not checked if empty.
+	ServerName string
+	Hostname   string
+
+	// TLSFiles optionally provides the CA bundle the chain is validated
+	// against instead of the system roots, and a client certificate.
+	TLSFiles *TLSFiles
+
+	// The target is degraded if the certificate expires within
+	// ExpiryWarning and unhealthy if it expires within ExpiryCritical.
+	// Expired certificates are always unhealthy.
+	ExpiryWarning  time.Duration
+	ExpiryCritical time.Duration
+
+	Retry RetryPolicy
+}
+
+func NewTLSChecker(address, serverName string) *TLSChecker {
+	return &TLSChecker{
+		Address:    address,
+		ServerName: serverName,
+		Retry:      SingleAttemptPolicy(5 * time.Second),
+	}
+}
+
+// Check implements gslb.HealthChecker.
+func (c *TLSChecker) Check(ctx context.Context) (gslb.Result, error) {
+	// Validate address before attempting to connect
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return gslb.Result{}, err
+	}
+
+	// The certificate is verified after the handshake, so that invalid
+	// certificates can still be inspected and reported.
+	cfg := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: true,
+	}
+
+	if c.TLSFiles != nil {
+		if err := c.TLSFiles.Apply(cfg); err != nil {
+			return gslb.Result{}, fmt.Errorf("loading TLS files: %w", err)
+		}
+	}
+
+	return c.Retry.Run(ctx, func(ctx context.Context) gslb.Result {
+		return c.check(ctx, cfg)
+	})
+}
+
+func (c *TLSChecker) check(ctx context.Context, cfg *tls.Config) gslb.Result {
+	start := time.Now()
+
+	conn, err := dialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return failure(errorClass(err), err.Error(), time.Since(start))
+	}
+	defer conn.Close() //nolint:errcheck
+
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return failure(errorClass(err), fmt.Sprintf("TLS handshake: %s", err), time.Since(start))
+	}
+
+	latency := time.Since(start)
+	certs := tlsConn.ConnectionState().PeerCertificates
+	leaf := certs[0]
+
+	res := gslb.Result{
+		State:   gslb.StateHealthy,
+		Latency: latency,
+		Details: map[string]string{
+			"subject":   leaf.Subject.String(),
+			"issuer":    leaf.Issuer.String(),
+			"not_after": leaf.NotAfter.UTC().Format(time.RFC3339),
+		},
+	}
+
+	remaining := time.Until(leaf.NotAfter)
+	status := fmt.Sprintf("certificate valid until %s", leaf.NotAfter.UTC().Format(time.DateOnly))
+
+	var problems []string
+	unhealthy := false
+
+	if err := verifyChain(certs, cfg.RootCAs); err != nil {
+		problems = append(problems, err.Error())
+		unhealthy = true
+	}
+
+	if c.Hostname != "" {
+		if err := leaf.VerifyHostname(c.Hostname); err != nil {
+			problems = append(problems, fmt.Sprintf("certificate does not cover %s", c.Hostname))
+			unhealthy = true
+		}
+	}
+
+	switch {
+	case remaining <= 0:
+		status = fmt.Sprintf("certificate expired on %s", leaf.NotAfter.UTC().Format(time.DateOnly))
+		unhealthy = true
+	case remaining < c.ExpiryCritical:
+		problems = append(problems, fmt.Sprintf("expires in %s", formatDays(remaining)))
+		unhealthy = true
+	case remaining < c.ExpiryWarning:
+		problems = append(problems, fmt.Sprintf("expires in %s", formatDays(remaining)))
+		res.State = gslb.StateDegraded
+	}
+
+	if unhealthy {
+		res.State = gslb.StateUnhealthy
+	}
+
+	if res.State != gslb.StateHealthy {
+		res.Class = gslb.ErrorTLS
+	}
+
+	res.Status = status
+	if len(problems) > 0 {
+		res.Status += ": " + strings.Join(problems, "; ")
+	}
+
+	return res
+}
+
+// verifyChain validates the certificates presented against roots, or the
+// system roots if nil. Expiry is judged separately.
+func verifyChain(certs []*x509.Certificate, roots *x509.CertPool) error {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	// Verify at a time the leaf is valid, its expiry is reported on its own
+	leaf := certs[0]
+	now := time.Now()
+	if now.After(leaf.NotAfter) {
+		now = leaf.NotAfter.Add(-time.Second)
+	}
+
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		return fmt.Errorf("chain validation failed: %w", err)
+	}
+
+	return nil
+}
+
+// formatDays formats a duration in days, or in hours below a day.
+func formatDays(d time.Duration) string {
+	if d < 24*time.Hour {
+		return d.Round(time.Hour).String()
+	}
+
+	return fmt.Sprintf("%d days", int(d/(24*time.Hour)))
+}
@@ -0,0 +1,157 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// startTLSServer serves cert on a local port and returns its address.
+func startTLSServer(t *testing.T, cert tls.Certificate) string {
+	t.Helper()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() }) //nolint:errcheck
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()           //nolint:errcheck
+				conn.(*tls.Conn).Handshake() //nolint:errcheck
+			}()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestTLSChecker_Check(t *testing.T) {
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name       string
+		validFor   time.Duration
+		hostname   string
+		trustCA    bool
+		wantState  gslb.State
+		wantStatus string
+	}{
+		{
+			name:       "valid",
+			validFor:   90 * day,
+			hostname:   "localhost",
+			trustCA:    true,
+			wantState:  gslb.StateHealthy,
+			wantStatus: "certificate valid until",
+		},
+		{
+			name:       "expires within warning",
+			validFor:   20 * day,
+			hostname:   "localhost",
+			trustCA:    true,
+			wantState:  gslb.StateDegraded,
+			wantStatus: "expires in 19 days",
+		},
+		{
+			name:       "expires within critical",
+			validFor:   5 * day,
+			hostname:   "localhost",
+			trustCA:    true,
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: "expires in 4 days",
+		},
+		{
+			name:       "expired",
+			validFor:   -day,
+			hostname:   "localhost",
+			trustCA:    true,
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: "certificate expired on",
+		},
+		{
+			name:       "hostname not covered",
+			validFor:   90 * day,
+			hostname:   "api.example.com",
+			trustCA:    true,
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: "does not cover api.example.com",
+		},
+		{
+			name:       "untrusted chain",
+			validFor:   90 * day,
+			hostname:   "localhost",
+			wantState:  gslb.StateUnhealthy,
+			wantStatus: "chain validation failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := newTestCertificate(t, time.Now().Add(tt.validFor))
+			addr := startTLSServer(t, cert)
+
+			checker := NewTLSChecker(addr, "localhost")
+			checker.Hostname = tt.hostname
+			checker.ExpiryWarning = 30 * day
+			checker.ExpiryCritical = 7 * day
+
+			if tt.trustCA {
+				caFile, _ := writeTestCertificate(t, t.TempDir(), cert)
+				files, err := NewTLSFiles(caFile, "", "")
+				if err != nil {
+					t.Fatalf("NewTLSFiles() failed: %v", err)
+				}
+				checker.TLSFiles = files
+			}
+
+			res, err := checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+
+			if res.State != tt.wantState {
+				t.Errorf("Check() state = %v, want %v (status %q)", res.State, tt.wantState, res.Status)
+			}
+			if !strings.Contains(res.Status, tt.wantStatus) {
+				t.Errorf("Check() status = %q, want %q", res.Status, tt.wantStatus)
+			}
+			if res.Details["not_after"] == "" {
+				t.Errorf("Check() details missing not_after")
+			}
+		})
+	}
+}
+
+func TestTLSChecker_Check_NotTLS(t *testing.T) {
+	addr := startTCPServer(t, func(c net.Conn) {
+		c.Write([]byte("220 mail.example.com ESMTP ready\r\n")) //nolint:errcheck
+	})
+
+	res, err := NewTLSChecker(addr, "localhost").Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	if res.State != gslb.StateUnhealthy || res.Class != gslb.ErrorTLS {
+		t.Errorf("Check() = %v/%q, want unhealthy tls (status %q)", res.State, res.Class, res.Status)
+	}
+}
+
+func TestTLSChecker_Check_InvalidAddress(t *testing.T) {
+	if _, err := NewTLSChecker("localhost", "").Check(context.Background()); err == nil {
+		t.Errorf("Check() expected error for invalid address, got nil")
+	}
+}
@@ -0,0 +1,186 @@
+package checkers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSFiles provides a CA bundle and a client certificate loaded from disk.
+// The files are checked for changes whenever they are used and reloaded after
+// they were rotated, so no restart is needed.
+type TLSFiles struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+
+	mu       sync.Mutex
+	caStamp  string
+	caPool   *x509.CertPool
+	crtStamp string
+	crt      *tls.Certificate
+}
+
+func NewTLSFiles(caFile, certFile, keyFile string) (*TLSFiles, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("client certificate and key must be set together")
+	}
+
+	f := &TLSFiles{
+		CAFile:   caFile,
+		CertFile: certFile,
+		KeyFile:  keyFile,
+	}
+
+	// Load once to report configuration errors early
+	if _, err := f.rootCAs(); err != nil {
+		return nil, err
+	}
+	if _, err := f.clientCertificate(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Apply configures cfg to use the CA bundle and client certificate.
+func (f *TLSFiles) Apply(cfg *tls.Config) error {
+	pool, err := f.rootCAs()
+	if err != nil {
+		return err
+	}
+
+	if pool != nil {
+		cfg.RootCAs = pool
+	}
+
+	if f.CertFile != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return f.clientCertificate()
+		}
+	}
+
+	return nil
+}
+
+func (f *TLSFiles) rootCAs() (*x509.CertPool, error) {
+	if f.CAFile == "" {
+		return nil, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stamp, err := fileStamp(f.CAFile)
+	if err != nil {
+		return keepCached(f.caPool, err)
+	}
+
+	if stamp == f.caStamp {
+		return f.caPool, nil
+	}
+
+	pem, err := os.ReadFile(f.CAFile)
+	if err != nil {
+		return keepCached(f.caPool, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return keepCached(f.caPool, fmt.Errorf("no certificates found in %s", f.CAFile))
+	}
+
+	if f.caPool != nil {
+		slog.Info("Reloaded CA bundle", "file", f.CAFile)
+	}
+
+	f.caStamp = stamp
+	f.caPool = pool
+
+	return pool, nil
+}
+
+func (f *TLSFiles) clientCertificate() (*tls.Certificate, error) {
+	if f.CertFile == "" {
+		// An empty certificate tells the server we have none
+		return &tls.Certificate{}, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	certStamp, err := fileStamp(f.CertFile)
+	if err != nil {
+		return keepCached(f.crt, err)
+	}
+
+	keyStamp, err := fileStamp(f.KeyFile)
+	if err != nil {
+		return keepCached(f.crt, err)
+	}
+
+	stamp := certStamp + "|" + keyStamp
+	if stamp == f.crtStamp {
+		return f.crt, nil
+	}
+
+	crt, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+	if err != nil {
+		// Certificate and key may be replaced one after the other, so a
+		// mismatch is expected for a short time during rotation.
+		return keepCached(f.crt, err)
+	}
+
+	if f.crt != nil {
+		slog.Info("Reloaded client certificate", "file", f.CertFile)
+	}
+
+	f.crtStamp = stamp
+	f.crt = &crt
+
+	return f.crt, nil
+}
+
+// keepCached returns the previously loaded value if there is one, so a
+// rotation in progress doesn't break checks, and err otherwise.
+func keepCached[T any](cached *T, err error) (*T, error) {
+	if cached == nil {
+		return nil, err
+	}
+
+	slog.Warn("Reloading TLS file failed, keeping previous version", "error", err)
+
+	return cached, nil
+}
+
+// fileStamp identifies the current version of a file by size and mtime.
+func fileStamp(name string) (string, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d-%s", fi.Size(), fi.ModTime().Format(time.RFC3339Nano)), nil
+}
+
+// ParseTLSVersion parses a TLS version like "1.2" into its tls constant.
+func ParseTLSVersion(s string) (uint16, error) {
+	switch s {
+	case "":
+		return 0, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q", s)
+	}
+}
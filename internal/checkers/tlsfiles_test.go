@@ -0,0 +1,168 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSimpleHTTPChecker_CheckHealth_MutualTLS(t *testing.T) {
+	serverCert := newTestCertificate(t, time.Now().Add(24*time.Hour))
+	clientCert := newTestCertificate(t, time.Now().Add(24*time.Hour))
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert.Leaf)
+
+	var gotClientCert bool
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotClientCert = len(r.TLS.PeerCertificates) > 0
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile, _ := writeTestCertificate(t, t.TempDir(), serverCert)
+	certFile, keyFile := writeTestCertificate(t, t.TempDir(), clientCert)
+
+	files, err := NewTLSFiles(caFile, certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewTLSFiles() failed: %v", err)
+	}
+
+	checker := NewSimpleHTTPChecker(server.URL, false)
+	checker.TLSFiles = files
+	checker.MinTLSVersion = tls.VersionTLS12
+
+	healthy, statusMsg, err := checker.CheckHealth(context.Background())
+	if err != nil {
+		t.Fatalf("CheckHealth() unexpected error: %v", err)
+	}
+	if !healthy {
+		t.Errorf("CheckHealth() healthy = false, want true (status %q)", statusMsg)
+	}
+	if !gotClientCert {
+		t.Errorf("Expected server to receive a client certificate")
+	}
+}
+
+func TestTLSFiles_Reload(t *testing.T) {
+	dir := t.TempDir()
+
+	first := newTestCertificate(t, time.Now().Add(24*time.Hour))
+	certFile, keyFile := writeTestCertificate(t, dir, first)
+
+	files, err := NewTLSFiles(certFile, certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewTLSFiles() failed: %v", err)
+	}
+
+	crt, err := files.clientCertificate()
+	if err != nil {
+		t.Fatalf("clientCertificate() failed: %v", err)
+	}
+	if !bytes.Equal(crt.Certificate[0], first.Certificate[0]) {
+		t.Fatalf("Expected first certificate to be loaded")
+	}
+
+	// Rotate the certificate on disk
+	second := newTestCertificate(t, time.Now().Add(48*time.Hour))
+	writeTestCertificate(t, dir, second)
+
+	crt, err = files.clientCertificate()
+	if err != nil {
+		t.Fatalf("clientCertificate() failed: %v", err)
+	}
+	if !bytes.Equal(crt.Certificate[0], second.Certificate[0]) {
+		t.Errorf("Expected rotated certificate to be loaded")
+	}
+
+	pool, err := files.rootCAs()
+	if err != nil {
+		t.Fatalf("rootCAs() failed: %v", err)
+	}
+	if _, err := second.Leaf.Verify(x509.VerifyOptions{Roots: pool}); err != nil {
+		t.Errorf("Expected rotated CA bundle to be loaded: %v", err)
+	}
+
+	// A half-written rotation keeps the previous certificate
+	if err := os.WriteFile(keyFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	crt, err = files.clientCertificate()
+	if err != nil {
+		t.Fatalf("clientCertificate() unexpected error: %v", err)
+	}
+	if !bytes.Equal(crt.Certificate[0], second.Certificate[0]) {
+		t.Errorf("Expected previous certificate to be kept")
+	}
+}
+
+func TestNewTLSFiles_Errors(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, newTestCertificate(t, time.Now().Add(time.Hour)))
+
+	invalidCA := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalidCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		caFile   string
+		certFile string
+		keyFile  string
+	}{
+		{name: "certificate without key", certFile: certFile},
+		{name: "key without certificate", keyFile: keyFile},
+		{name: "missing CA file", caFile: filepath.Join(dir, "missing.pem")},
+		{name: "invalid CA file", caFile: invalidCA},
+		{name: "mismatched key", certFile: certFile, keyFile: invalidCA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTLSFiles(tt.caFile, tt.certFile, tt.keyFile); err == nil {
+				t.Errorf("NewTLSFiles() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    uint16
+		wantErr bool
+	}{
+		{input: "", want: 0},
+		{input: "1.2", want: tls.VersionTLS12},
+		{input: "1.3", want: tls.VersionTLS13},
+		{input: "TLS1.3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTLSVersion(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTLSVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseTLSVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package checkers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// WebhookReceiver accepts health pushed by a monitoring stack, either as
+// Alertmanager webhook or in a generic JSON form, and keeps it until it is
+// resolved or expires. Its checkers turn the state into health results.
+//
+// Alertmanager payloads are accepted on /alertmanager. The host an alert
+// applies to is taken from the HostLabel label. The generic form is accepted
+// on /health:
+//
+//	{"host": "api.example.com", "state": "unhealthy", "status": "maintenance", "ttl": "10m"}
+//
+// A healthy state resolves an earlier push with the same host and id.
+type WebhookReceiver struct {
+	// HostLabel is the alert label holding the host, "gslb_host" if empty.
+	HostLabel string
+
+	// TTL expires generic pushes without ttl, zero means five minutes.
+	TTL time.Duration
+
+	// AlertTTL, if set, expires firing alerts without endsAt that are not
+	// sent again within it, in case a resolved notification gets lost. It
+	// must be longer than the Alertmanager repeat_interval, which defaults
+	// to four hours. Zero keeps such alerts until they are resolved.
+	AlertTTL time.Duration
+
+	// Token, if set, must be sent as bearer token.
+	Token string
+
+	mu      sync.Mutex
+	entries map[string]webhookEntry
+	mux     *http.ServeMux
+	now     func() time.Time
+}
+
+const (
+	defaultWebhookHostLabel = "gslb_host"
+	defaultWebhookTTL       = 5 * time.Minute
+)
+
+// webhookEntry is a firing alert or generic push. Alerts carry labels and
+// get their state from the checker, generic pushes bring their own state.
+// Entries with a zero expiry are kept until they are resolved.
+type webhookEntry struct {
+	host    string
+	labels  map[string]string
+	state   gslb.State
+	status  string
+	expires time.Time
+}
+
+func (e webhookEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !e.expires.After(now)
+}
+
+func NewWebhookReceiver() *WebhookReceiver {
+	r := &WebhookReceiver{
+		entries: map[string]webhookEntry{},
+		mux:     http.NewServeMux(),
+		now:     time.Now,
+	}
+
+	r.mux.HandleFunc("POST /alertmanager", r.handleAlertmanager)
+	r.mux.HandleFunc("POST /health", r.handleGeneric)
+
+	return r
+}
+
+// ServeHTTP implements http.Handler.
+func (r *WebhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.Token != "" {
+		token, _ := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(r.Token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	r.mux.ServeHTTP(w, req)
+}
+
+type alertmanagerPayload struct {
+	Alerts []struct {
+		Status      string            `json:"status"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+		EndsAt      time.Time         `json:"endsAt"`
+		Fingerprint string            `json:"fingerprint"`
+	} `json:"alerts"`
+}
+
+func (r *WebhookReceiver) handleAlertmanager(w http.ResponseWriter, req *http.Request) {
+	var payload alertmanagerPayload
+	if err := decodeWebhook(req, &payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hostLabel := r.HostLabel
+	if hostLabel == "" {
+		hostLabel = defaultWebhookHostLabel
+	}
+
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, a := range payload.Alerts {
+		host := a.Labels[hostLabel]
+		if host == "" {
+			continue
+		}
+
+		key := "alert:" + a.Fingerprint
+		if a.Fingerprint == "" {
+			key = "alert:" + labelsKey(a.Labels)
+		}
+
+		if a.Status == "resolved" || (!a.EndsAt.IsZero() && !a.EndsAt.After(now)) {
+			delete(r.entries, key)
+			continue
+		}
+
+		status := a.Labels["alertname"]
+		if summary := a.Annotations["summary"]; summary != "" {
+			status += " (" + summary + ")"
+		}
+
+		r.store(key, webhookEntry{
+			host:    host,
+			labels:  a.Labels,
+			status:  status,
+			expires: r.alertExpiry(now, a.EndsAt),
+		})
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type genericPush struct {
+	Host   string `json:"host"`
+	ID     string `json:"id"`
+	State  string `json:"state"`
+	Status string `json:"status"`
+	TTL    string `json:"ttl"`
+}
+
+func (r *WebhookReceiver) handleGeneric(w http.ResponseWriter, req *http.Request) {
+	var push genericPush
+	if err := decodeWebhook(req, &push); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if push.Host == "" {
+		http.Error(w, "host must be set", http.StatusBadRequest)
+		return
+	}
+
+	var ttl time.Duration
+	if push.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(push.TTL); err != nil || ttl <= 0 {
+			http.Error(w, fmt.Sprintf("invalid ttl %q", push.TTL), http.StatusBadRequest)
+			return
+		}
+	}
+
+	key := "push:" + push.Host + ":" + push.ID
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch push.State {
+	case "healthy", "resolved":
+		delete(r.entries, key)
+	case "degraded", "unhealthy":
+		state := gslb.StateUnhealthy
+		if push.State == "degraded" {
+			state = gslb.StateDegraded
+		}
+
+		if ttl <= 0 {
+			ttl = r.TTL
+		}
+		if ttl <= 0 {
+			ttl = defaultWebhookTTL
+		}
+
+		r.store(key, webhookEntry{
+			host:    push.Host,
+			state:   state,
+			status:  push.Status,
+			expires: now.Add(ttl),
+		})
+	default:
+		http.Error(w, fmt.Sprintf("unsupported state %q", push.State), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeWebhook decodes a JSON request body of limited size.
+func decodeWebhook(req *http.Request, v any) error {
+	if err := json.NewDecoder(io.LimitReader(req.Body, maxBodySize)).Decode(v); err != nil {
+		return fmt.Errorf("decoding payload: %w", err)
+	}
+
+	return nil
+}
+
+// alertExpiry returns endsAt, or AlertTTL from now if it is not set.
+// Alertmanager sends firing alerts without endsAt and repeats them only
+// every repeat_interval, so they are kept until resolved by default.
+func (r *WebhookReceiver) alertExpiry(now, endsAt time.Time) time.Time {
+	if !endsAt.IsZero() || r.AlertTTL <= 0 {
+		return endsAt
+	}
+
+	return now.Add(r.AlertTTL)
+}
+
+// store saves an entry and drops expired ones. r.mu must be held.
+func (r *WebhookReceiver) store(key string, e webhookEntry) {
+	now := r.now()
+	maps.DeleteFunc(r.entries, func(_ string, e webhookEntry) bool {
+		return e.expired(now)
+	})
+
+	r.entries[key] = e
+}
+
+// active returns the unexpired entries for host.
+func (r *WebhookReceiver) active(host string) []webhookEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+
+	var entries []webhookEntry
+	for _, e := range r.entries {
+		if e.host == host && !e.expired(now) {
+			entries = append(entries, e)
+		}
+	}
+
+	// Map iteration is random, keep the status stable
+	slices.SortFunc(entries, func(a, b webhookEntry) int {
+		return strings.Compare(a.status, b.status)
+	})
+
+	return entries
+}
+
+// Checker returns a checker reporting the pushed health of host.
+func (r *WebhookReceiver) Checker(host string) *WebhookChecker {
+	return &WebhookChecker{Receiver: r, Host: host}
+}
+
+// WebhookChecker reports the health pushed to a WebhookReceiver for Host.
+// Firing alerts make the host unhealthy if they carry all labels of
+// UnhealthyLabels and degraded if they carry all of DegradedLabels. Alerts
+// matching neither are ignored. Without any labels configured, every alert
+// makes the host unhealthy. Generic pushes carry their own state.
+type WebhookChecker struct {
+	Receiver        *WebhookReceiver
+	Host            string
+	UnhealthyLabels map[string]string
+	DegradedLabels  map[string]string
+}
+
+// Check implements gslb.HealthChecker.
+func (c *WebhookChecker) Check(ctx context.Context) (gslb.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return gslb.Result{}, err
+	}
+
+	res := gslb.Result{State: gslb.StateHealthy}
+
+	var reasons []string
+	for _, e := range c.Receiver.active(c.Host) {
+		state := e.state
+		if e.labels != nil {
+			var ok bool
+			if state, ok = c.alertState(e.labels); !ok {
+				continue
+			}
+		}
+
+		// The worst state wins
+		if state == gslb.StateUnhealthy || res.State == gslb.StateHealthy {
+			res.State = state
+		}
+
+		reasons = append(reasons, fmt.Sprintf("%s: %s", state, e.status))
+	}
+
+	res.Details = map[string]string{"pushed": strconv.Itoa(len(reasons))}
+
+	if len(reasons) == 0 {
+		res.Status = "no health pushed"
+		return res, nil
+	}
+
+	if res.State == gslb.StateUnhealthy {
+		res.Class = gslb.ErrorResponse
+	}
+
+	res.Status = fmt.Sprintf("%d pushed: %s", len(reasons), strings.Join(reasons, "; "))
+
+	return res, nil
+}
+
+// alertState maps alert labels to a state, reporting false if the alert is
+// not relevant.
+func (c *WebhookChecker) alertState(labels map[string]string) (gslb.State, bool) {
+	switch {
+	case len(c.UnhealthyLabels) == 0 && len(c.DegradedLabels) == 0:
+		return gslb.StateUnhealthy, true
+	case len(c.UnhealthyLabels) > 0 && matchLabels(labels, c.UnhealthyLabels):
+		return gslb.StateUnhealthy, true
+	case len(c.DegradedLabels) > 0 && matchLabels(labels, c.DegradedLabels):
+		return gslb.StateDegraded, true
+	default:
+		return 0, false
+	}
+}
+
+// matchLabels reports whether labels contain all matchers.
+func matchLabels(labels, matchers map[string]string) bool {
+	for k, v := range matchers {
+		if labels[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// labelsKey identifies an alert without fingerprint by its labels.
+func labelsKey(labels map[string]string) string {
+	keys := slices.Sorted(maps.Keys(labels))
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%q,", k, labels[k])
+	}
+
+	return b.String()
+}
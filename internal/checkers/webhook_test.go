@@ -0,0 +1,195 @@
+package checkers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// postWebhook posts body to the receiver and returns the response code.
+func postWebhook(t *testing.T, r *WebhookReceiver, path, token, body string) int {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	return rec.Code
+}
+
+func checkWebhook(t *testing.T, c *WebhookChecker) gslb.Result {
+	t.Helper()
+
+	res, err := c.Check(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return res
+}
+
+func TestWebhookChecker_Alertmanager(t *testing.T) {
+	r := NewWebhookReceiver()
+
+	c := r.Checker("api.example.com")
+	c.UnhealthyLabels = map[string]string{"severity": "critical"}
+	c.DegradedLabels = map[string]string{"severity": "warning"}
+
+	if res := checkWebhook(t, c); res.State != gslb.StateHealthy || res.Status != "no health pushed" {
+		t.Fatalf("expected healthy without pushes, got %s: %s", res.State, res.Status)
+	}
+
+	firing := `{"alerts":[
+		{"status":"firing","fingerprint":"a","labels":{"alertname":"HighLatency","severity":"warning","gslb_host":"api.example.com"}},
+		{"status":"firing","fingerprint":"b","labels":{"alertname":"Other","severity":"warning","gslb_host":"other.example.com"}},
+		{"status":"firing","fingerprint":"c","labels":{"alertname":"Info","severity":"info","gslb_host":"api.example.com"}}]}`
+	if code := postWebhook(t, r, "/alertmanager", "", firing); code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", code)
+	}
+
+	res := checkWebhook(t, c)
+	if res.State != gslb.StateDegraded || res.Status != "1 pushed: degraded: HighLatency" {
+		t.Errorf("expected degraded by HighLatency, got %s: %s", res.State, res.Status)
+	}
+
+	critical := `{"alerts":[{"status":"firing","fingerprint":"d",
+		"labels":{"alertname":"PrimaryDown","severity":"critical","gslb_host":"api.example.com"},
+		"annotations":{"summary":"all backends down"}}]}`
+	postWebhook(t, r, "/alertmanager", "", critical)
+
+	res = checkWebhook(t, c)
+	if res.State != gslb.StateUnhealthy || res.Class != gslb.ErrorResponse {
+		t.Errorf("expected unhealthy, got %s (%s): %s", res.State, res.Class, res.Status)
+	}
+	if !strings.Contains(res.Status, "unhealthy: PrimaryDown (all backends down)") {
+		t.Errorf("expected alert summary in status, got %q", res.Status)
+	}
+	if res.Details["pushed"] != "2" {
+		t.Errorf("expected 2 pushed, got %q", res.Details["pushed"])
+	}
+
+	resolved := `{"alerts":[
+		{"status":"resolved","fingerprint":"a","labels":{"gslb_host":"api.example.com"}},
+		{"status":"firing","fingerprint":"d","labels":{"gslb_host":"api.example.com"},"endsAt":"2000-01-01T00:00:00Z"}]}`
+	postWebhook(t, r, "/alertmanager", "", resolved)
+
+	if res := checkWebhook(t, c); res.State != gslb.StateHealthy {
+		t.Errorf("expected healthy after resolve, got %s: %s", res.State, res.Status)
+	}
+}
+
+func TestWebhookChecker_AlertmanagerWithoutLabels(t *testing.T) {
+	r := NewWebhookReceiver()
+	r.HostLabel = "instance"
+
+	c := r.Checker("api.example.com")
+
+	postWebhook(t, r, "/alertmanager", "", `{"alerts":[{"status":"firing","labels":{"alertname":"Down","instance":"api.example.com"}}]}`)
+
+	if res := checkWebhook(t, c); res.State != gslb.StateUnhealthy {
+		t.Errorf("expected any alert to be unhealthy, got %s: %s", res.State, res.Status)
+	}
+}
+
+func TestWebhookChecker_AlertmanagerWithoutEndsAt(t *testing.T) {
+	now := time.Now()
+
+	r := NewWebhookReceiver()
+	r.now = func() time.Time { return now }
+
+	c := r.Checker("api.example.com")
+
+	// Alertmanager sends firing alerts with a zero endsAt and repeats them
+	// only every repeat_interval
+	firing := `{"alerts":[{"status":"firing","fingerprint":"a","endsAt":"0001-01-01T00:00:00Z",
+		"labels":{"alertname":"PrimaryDown","gslb_host":"api.example.com"}}]}`
+	postWebhook(t, r, "/alertmanager", "", firing)
+
+	now = now.Add(time.Hour)
+	if res := checkWebhook(t, c); res.State != gslb.StateUnhealthy {
+		t.Errorf("expected unhealthy until resolved, got %s: %s", res.State, res.Status)
+	}
+
+	// With a safety net, alerts not repeated in time expire
+	r.AlertTTL = 5 * time.Hour
+	postWebhook(t, r, "/alertmanager", "", firing)
+
+	now = now.Add(4 * time.Hour)
+	if res := checkWebhook(t, c); res.State != gslb.StateUnhealthy {
+		t.Errorf("expected unhealthy within alert TTL, got %s: %s", res.State, res.Status)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if res := checkWebhook(t, c); res.State != gslb.StateHealthy {
+		t.Errorf("expected healthy after alert TTL, got %s: %s", res.State, res.Status)
+	}
+}
+
+func TestWebhookChecker_Generic(t *testing.T) {
+	r := NewWebhookReceiver()
+	c := r.Checker("api.example.com")
+
+	postWebhook(t, r, "/health", "", `{"host":"api.example.com","id":"deploy","state":"degraded","status":"deployment running"}`)
+
+	res := checkWebhook(t, c)
+	if res.State != gslb.StateDegraded || res.Status != "1 pushed: degraded: deployment running" {
+		t.Errorf("expected degraded, got %s: %s", res.State, res.Status)
+	}
+
+	postWebhook(t, r, "/health", "", `{"host":"api.example.com","id":"deploy","state":"healthy"}`)
+
+	if res := checkWebhook(t, c); res.State != gslb.StateHealthy {
+		t.Errorf("expected healthy after resolve, got %s: %s", res.State, res.Status)
+	}
+
+	postWebhook(t, r, "/health", "", `{"host":"api.example.com","state":"unhealthy","status":"maintenance","ttl":"50ms"}`)
+
+	if res := checkWebhook(t, c); res.State != gslb.StateUnhealthy {
+		t.Errorf("expected unhealthy, got %s: %s", res.State, res.Status)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if res := checkWebhook(t, c); res.State != gslb.StateHealthy {
+		t.Errorf("expected healthy after ttl, got %s: %s", res.State, res.Status)
+	}
+}
+
+func TestWebhookReceiver_Errors(t *testing.T) {
+	r := NewWebhookReceiver()
+	r.Token = "secret"
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		body  string
+		want  int
+	}{
+		{"missing token", "/health", "", `{"host":"a","state":"unhealthy"}`, http.StatusUnauthorized},
+		{"wrong token", "/health", "wrong", `{"host":"a","state":"unhealthy"}`, http.StatusUnauthorized},
+		{"valid", "/health", "secret", `{"host":"a","state":"unhealthy"}`, http.StatusNoContent},
+		{"invalid json", "/alertmanager", "secret", `{"alerts":`, http.StatusBadRequest},
+		{"missing host", "/health", "secret", `{"state":"unhealthy"}`, http.StatusBadRequest},
+		{"unsupported state", "/health", "secret", `{"host":"a","state":"broken"}`, http.StatusBadRequest},
+		{"invalid ttl", "/health", "secret", `{"host":"a","state":"unhealthy","ttl":"soon"}`, http.StatusBadRequest},
+		{"unknown path", "/other", "secret", `{}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := postWebhook(t, r, tt.path, tt.token, tt.body); code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package gslb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+// evalActive checks all targets and points the record to every available
+// one. A target in the record stays until it failed Fall times, others are
+// added after Rise healthy evaluations unless flap damping suppresses them.
+// Removing a failed target counts as a flap of it.
+func (s *switcher) evalActive(ctx context.Context) error {
+	// Get GSLB record state
+	recs, err := s.multi.GetCurrentIPs(ctx)
+	if err != nil {
+		return fmt.Errorf("getting GSLB record IPs: %w", err)
+	}
+
+	in := make([]bool, len(s.targets))
+	for _, rec := range recs {
+		if i := s.index(rec); i >= 0 {
+			in[i] = true
+		}
+	}
+
+	results, err := s.checkAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	var want []int
+	for i, t := range s.targets {
+		// Degraded targets still serve traffic and are not removed
+		healthy := results[i].Healthy()
+		st := &s.states[i]
+		st.count(healthy)
+
+		if healthy {
+			st.healthy = s.now()
+		}
+
+		switch {
+		case in[i] && st.fall < s.policy.Fall:
+			if !healthy {
+				log.Printf("Target %s unhealthy (fall %d/%d), keeping it in GSLB record: %s", t.Name, st.fall, s.policy.Fall, t.IP)
+			}
+
+			want = append(want, i)
+		case in[i] || !healthy:
+			// Failed or still unhealthy
+		case st.rise < s.policy.Rise:
+			log.Printf("Target %s healthy (rise %d/%d), not yet adding it to GSLB record: %s", t.Name, st.rise, s.policy.Rise, t.IP)
+		case st.damping.suppressed(s.now()):
+			log.Printf("Flap damping holding back adding %s IP (penalty %.2f, reuse at %.2f): %s",
+				t.Name, st.damping.current(s.now()), st.damping.reuse(), t.IP)
+		default:
+			want = append(want, i)
+		}
+	}
+
+	if len(want) == 0 {
+		return s.noneActive(ctx, recs, in)
+	}
+
+	return s.setActive(ctx, recs, in, want)
+}
+
+// noneActive applies the both down policy in active-active mode. Rather than
+// leaving the record with failed targets only, keeping it falls back to the
+// last healthy target.
+func (s *switcher) noneActive(ctx context.Context, recs []string, in []bool) error {
+	switch s.policy.BothDown {
+	case BothDownPrimary, BothDownDisable:
+		cur := -1
+		if len(recs) == 1 {
+			cur = s.index(recs[0])
+		}
+
+		return s.bothDown(ctx, strings.Join(recs, ", "), cur)
+	}
+
+	last := -1
+	for i, st := range s.states {
+		if !st.healthy.IsZero() && (last < 0 || st.healthy.After(s.states[last].healthy)) {
+			last = i
+		}
+	}
+
+	if last < 0 {
+		log.Println("No target available, keeping GSLB record on IPs:", strings.Join(recs, ", "))
+		return nil
+	}
+
+	return s.setActive(ctx, recs, in, []int{last})
+}
+
+// setActive points the record to the wanted targets, if they differ from
+// the current IPs.
+func (s *switcher) setActive(ctx context.Context, recs []string, in []bool, want []int) error {
+	var ips, added, removed []string
+	for i, t := range s.targets {
+		switch wanted := slices.Contains(want, i); {
+		case wanted:
+			ips = append(ips, t.IP)
+			if !in[i] {
+				added = append(added, t.Name)
+			}
+		case in[i]:
+			removed = append(removed, t.Name)
+		}
+	}
+
+	// IPs of unknown targets are removed too
+	if len(added) == 0 && len(recs) == len(ips) {
+		return nil
+	}
+
+	if s.dwelling("IPs " + strings.Join(ips, ", ")) {
+		return nil
+	}
+
+	if err := s.multi.SetIPs(ctx, ips); err != nil {
+		return fmt.Errorf("updating GSLB record IPs: %w", err)
+	}
+
+	s.lastSwitch = s.now()
+
+	for i := range s.targets {
+		if in[i] && !slices.Contains(want, i) {
+			s.states[i].damping.flap(s.lastSwitch)
+		}
+	}
+
+	log.Printf("Set GSLB record IPs (added %s, removed %s): %s",
+		joinNames(added), joinNames(removed), strings.Join(ips, ", "))
+
+	return nil
+}
+
+// joinNames lists target names for logs.
+func joinNames(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, ", ")
+}
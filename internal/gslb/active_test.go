@@ -0,0 +1,170 @@
+package gslb
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// multiGslb is a pool provider with a multi-value record.
+type multiGslb struct {
+	poolGslb
+	current []string
+}
+
+func newMultiGslb(current ...string) *multiGslb {
+	return &multiGslb{
+		poolGslb: poolGslb{
+			ips: []string{"10.0.1.1", "10.0.2.1", "10.0.3.1"},
+			up:  map[string]bool{},
+		},
+		current: current,
+	}
+}
+
+func (m *multiGslb) GetCurrentIPs(ctx context.Context) ([]string, error) {
+	return m.current, nil
+}
+
+func (m *multiGslb) SetIPs(ctx context.Context, ips []string) error {
+	m.current = slices.Clone(ips)
+	return nil
+}
+
+func (m *multiGslb) SwitchToIP(ctx context.Context, ip string) error {
+	return m.SetIPs(ctx, []string{ip})
+}
+
+func (m *multiGslb) DisableRecord(ctx context.Context) error {
+	return m.SetIPs(ctx, nil)
+}
+
+func TestGslbEvalActive(t *testing.T) {
+	g := newMultiGslb("10.0.1.1")
+	s := newSwitcher(g, SwitchPolicy{Mode: ModeActiveActive, Rise: 2})
+
+	steps := []struct {
+		up   []bool
+		want string
+	}{
+		// Healthy targets are added after Rise evaluations
+		{[]bool{true, true, false}, "10.0.1.1"},
+		{[]bool{true, true, true}, "10.0.1.1,10.0.2.1"},
+		{[]bool{true, true, true}, "10.0.1.1,10.0.2.1,10.0.3.1"},
+		// Failed targets are removed
+		{[]bool{false, true, true}, "10.0.2.1,10.0.3.1"},
+		{[]bool{false, true, false}, "10.0.2.1"},
+		// Without any healthy target, the last healthy one stays
+		{[]bool{false, false, false}, "10.0.2.1"},
+		// A healthy target replaces it, even before Rise evaluations
+		{[]bool{false, false, true}, "10.0.3.1"},
+		{[]bool{false, true, true}, "10.0.3.1"},
+		{[]bool{false, true, true}, "10.0.2.1,10.0.3.1"},
+	}
+
+	for i, step := range steps {
+		for j, ip := range g.ips {
+			g.up[ip] = step.up[j]
+		}
+
+		if err := s.eval(context.Background()); err != nil {
+			t.Fatalf("step %d: eval() failed: %v", i, err)
+		}
+
+		if got := strings.Join(g.current, ","); got != step.want {
+			t.Fatalf("step %d: expected IPs %s, got %s", i, step.want, got)
+		}
+	}
+}
+
+func TestGslbEvalActiveBothDown(t *testing.T) {
+	tests := []struct {
+		policy BothDownPolicy
+		want   string
+	}{
+		{BothDownKeep, "10.0.2.1"},
+		{BothDownPrimary, "10.0.1.1"},
+		{BothDownDisable, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			g := newMultiGslb("10.0.1.1", "10.0.2.1")
+			g.up["10.0.2.1"] = true
+
+			s := newSwitcher(g, SwitchPolicy{Mode: ModeActiveActive, BothDown: tt.policy})
+			if err := s.eval(context.Background()); err != nil {
+				t.Fatalf("eval() failed: %v", err)
+			}
+
+			// All targets down
+			g.up["10.0.2.1"] = false
+			if err := s.eval(context.Background()); err != nil {
+				t.Fatalf("eval() failed: %v", err)
+			}
+
+			if got := strings.Join(g.current, ","); got != tt.want {
+				t.Errorf("expected IPs %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGslbEvalActiveSlow(t *testing.T) {
+	g := newMultiGslb("10.0.1.1", "10.0.2.1", "10.0.3.1")
+	g.up["10.0.3.1"] = true
+
+	// Every check takes most of the evaluation, the first two fail
+	g.delay = 100 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	s := newSwitcher(g, SwitchPolicy{Mode: ModeActiveActive})
+	if err := s.eval(ctx); err != nil {
+		t.Fatalf("eval() failed: %v", err)
+	}
+
+	if got := strings.Join(g.current, ","); got != "10.0.3.1" {
+		t.Errorf("expected IPs 10.0.3.1, got %s", got)
+	}
+}
+
+func TestGslbEvalActiveHangingCheck(t *testing.T) {
+	g := newMultiGslb("10.0.1.1", "10.0.2.1")
+	g.up["10.0.2.1"] = true
+	g.hang = map[string]bool{"10.0.1.1": true}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	s := newSwitcher(g, SwitchPolicy{Mode: ModeActiveActive})
+	if err := s.eval(ctx); err != nil {
+		t.Fatalf("eval() failed: %v", err)
+	}
+
+	if got := strings.Join(g.current, ","); got != "10.0.2.1" {
+		t.Errorf("expected IPs 10.0.2.1, got %s", got)
+	}
+}
+
+func TestRunActiveUnsupported(t *testing.T) {
+	if err := Run(context.Background(), newMockGslb(), 0, SwitchPolicy{Mode: ModeActiveActive}); err == nil {
+		t.Error("expected error for provider without multi-value records")
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if m, err := ParseMode(""); err != nil || m != ModeFailover {
+		t.Errorf("expected failover by default, got %q, %v", m, err)
+	}
+
+	if m, err := ParseMode("active-active"); err != nil || m != ModeActiveActive {
+		t.Errorf("expected active-active, got %q, %v", m, err)
+	}
+
+	if _, err := ParseMode("round-robin"); err == nil {
+		t.Error("expected error for unsupported mode")
+	}
+}
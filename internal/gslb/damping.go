@@ -0,0 +1,110 @@
+package gslb
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// FlapDamping suppresses automatic failback to a target that keeps
+// flapping, in the style of BGP route flap dampening.
+//
+// Every failover from a target adds a penalty of 1 to it that decays
+// exponentially with HalfLife. Once the penalty exceeds MaxFlaps, failback is
+// suppressed until it has decayed to half of MaxFlaps. The penalty is capped
+// so that suppression lasts at most MaxSuppress after the last failover.
+type FlapDamping struct {
+	// MaxFlaps is the number of failovers tolerated, zero disables
+	// damping.
+	MaxFlaps int
+
+	// HalfLife defaults to 15 minutes, MaxSuppress to four half-lives.
+	HalfLife    time.Duration
+	MaxSuppress time.Duration
+}
+
+const defaultDampingHalfLife = 15 * time.Minute
+
+// Validate reports settings under which failback could never be suppressed.
+// The penalty is capped at half of MaxFlaps doubled once per half-life of
+// MaxSuppress, which only exceeds MaxFlaps if MaxSuppress is longer than
+// HalfLife.
+func (f FlapDamping) Validate() error {
+	if f.MaxFlaps <= 0 || f.MaxSuppress <= 0 {
+		return nil
+	}
+
+	halfLife := f.HalfLife
+	if halfLife <= 0 {
+		halfLife = defaultDampingHalfLife
+	}
+
+	if f.MaxSuppress <= halfLife {
+		return fmt.Errorf("maximum suppression %s must be longer than the half-life %s", f.MaxSuppress, halfLife)
+	}
+
+	return nil
+}
+
+// damping tracks the penalty of a target.
+type damping struct {
+	cfg FlapDamping
+
+	penalty     float64
+	updated     time.Time
+	suppressing bool
+}
+
+func newDamping(cfg FlapDamping) *damping {
+	if cfg.HalfLife <= 0 {
+		cfg.HalfLife = defaultDampingHalfLife
+	}
+
+	if cfg.MaxSuppress <= 0 {
+		cfg.MaxSuppress = 4 * cfg.HalfLife
+	}
+
+	return &damping{cfg: cfg}
+}
+
+// reuse is the penalty failback is allowed again at.
+func (d *damping) reuse() float64 {
+	return float64(d.cfg.MaxFlaps) / 2
+}
+
+// current returns the penalty decayed until now.
+func (d *damping) current(now time.Time) float64 {
+	if d.penalty == 0 {
+		return 0
+	}
+
+	elapsed := now.Sub(d.updated).Seconds()
+
+	return d.penalty * math.Exp2(-elapsed/d.cfg.HalfLife.Seconds())
+}
+
+// flap adds the penalty of a failover.
+func (d *damping) flap(now time.Time) {
+	if d.cfg.MaxFlaps <= 0 {
+		return
+	}
+
+	// Cap the penalty, so it decays to the reuse limit within MaxSuppress
+	ceiling := d.reuse() * math.Exp2(d.cfg.MaxSuppress.Seconds()/d.cfg.HalfLife.Seconds())
+
+	d.penalty = min(d.current(now)+1, ceiling)
+	d.updated = now
+
+	if d.penalty > float64(d.cfg.MaxFlaps) {
+		d.suppressing = true
+	}
+}
+
+// suppressed reports whether failback is currently suppressed.
+func (d *damping) suppressed(now time.Time) bool {
+	if d.suppressing && d.current(now) <= d.reuse() {
+		d.suppressing = false
+	}
+
+	return d.suppressing
+}
@@ -0,0 +1,92 @@
+package gslb
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDamping(t *testing.T) {
+	d := newDamping(FlapDamping{MaxFlaps: 2, HalfLife: time.Minute})
+	now := time.Now()
+
+	d.flap(now)
+	d.flap(now)
+	if d.suppressed(now) {
+		t.Fatal("expected no suppression after 2 flaps")
+	}
+
+	d.flap(now)
+	if !d.suppressed(now) {
+		t.Fatal("expected suppression after 3 flaps")
+	}
+
+	// Penalty 3 decays to 1.5 after one half-life, still above reuse
+	if p := d.current(now.Add(time.Minute)); math.Abs(p-1.5) > 0.001 {
+		t.Errorf("expected penalty 1.5 after one half-life, got %.3f", p)
+	}
+	if !d.suppressed(now.Add(time.Minute)) {
+		t.Error("expected suppression above reuse limit")
+	}
+
+	// Below the reuse limit of 1 after log2(3) half-lives
+	if d.suppressed(now.Add(2 * time.Minute)) {
+		t.Error("expected suppression to end at reuse limit")
+	}
+}
+
+func TestDampingMaxSuppress(t *testing.T) {
+	d := newDamping(FlapDamping{MaxFlaps: 2, HalfLife: time.Minute, MaxSuppress: 2 * time.Minute})
+	now := time.Now()
+
+	for range 50 {
+		d.flap(now)
+	}
+
+	// The penalty is capped at reuse * 2^(MaxSuppress/HalfLife)
+	if p := d.current(now); math.Abs(p-4) > 0.001 {
+		t.Errorf("expected penalty capped at 4, got %.3f", p)
+	}
+
+	if !d.suppressed(now.Add(2*time.Minute - time.Second)) {
+		t.Error("expected suppression within MaxSuppress")
+	}
+	if d.suppressed(now.Add(2 * time.Minute)) {
+		t.Error("expected suppression to end after MaxSuppress")
+	}
+}
+
+func TestDampingDisabled(t *testing.T) {
+	d := newDamping(FlapDamping{})
+	now := time.Now()
+
+	for range 10 {
+		d.flap(now)
+	}
+
+	if d.suppressed(now) {
+		t.Error("expected no suppression without MaxFlaps")
+	}
+}
+
+func TestFlapDampingValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     FlapDamping
+		wantErr bool
+	}{
+		{"disabled", FlapDamping{MaxSuppress: time.Second}, false},
+		{"defaults", FlapDamping{MaxFlaps: 3}, false},
+		{"longer than half-life", FlapDamping{MaxFlaps: 3, HalfLife: time.Minute, MaxSuppress: 2 * time.Minute}, false},
+		{"equal to half-life", FlapDamping{MaxFlaps: 3, HalfLife: time.Minute, MaxSuppress: time.Minute}, true},
+		{"shorter than default half-life", FlapDamping{MaxFlaps: 3, MaxSuppress: 10 * time.Minute}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,65 +2,434 @@ package gslb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"sync"
 	"time"
 )
 
 type Gslb interface {
-	CheckPrimaryHealth() (bool, error)
-	PrimaryIP() string
-	SecondaryIP() string
-	GetCurrentIP() (string, error)
-	SwitchToPrimaryIP() error
-	SwitchToSecondaryIP() error
+	// Targets returns the targets of the record in order of priority.
+	Targets() []Target
+	CheckHealth(ctx context.Context, t Target) (Result, error)
+	// GetCurrentIP returns the IP of the record, empty if it is disabled.
+	GetCurrentIP(ctx context.Context) (string, error)
+	// SwitchToIP points the record to ip, enabling a disabled record.
+	SwitchToIP(ctx context.Context, ip string) error
+	DisableRecord(ctx context.Context) error
 }
 
+// MultiGslb is a provider that can point the record to several IPs at once,
+// as needed by active-active mode.
+type MultiGslb interface {
+	Gslb
+	// GetCurrentIPs returns the IPs of the record, none if it is disabled.
+	GetCurrentIPs(ctx context.Context) ([]string, error)
+	// SetIPs points the record to ips, replacing all others.
+	SetIPs(ctx context.Context, ips []string) error
+}
+
+// HealthChecker checks the health of a target. Errors are reserved for
+// failures of the check itself, such as a misconfiguration or a cancelled
+// context, an unreachable target is reported as unhealthy.
 type HealthChecker interface {
-	CheckHealth() (bool, string, error)
+	Check(ctx context.Context) (Result, error)
+}
+
+// Target is an IP the GSLB record can point to.
+type Target struct {
+	// Name identifies the target in logs.
+	Name string
+	IP   string
+
+	// HealthChecker is optional, without it the target is assumed to be
+	// healthy.
+	HealthChecker HealthChecker
 }
 
 type GslbConfig struct {
-	Host                 string
+	Host string
+
+	// Targets in order of priority, the record points to the first
+	// available one. Without targets, the primary and the secondary below
+	// are used.
+	Targets []Target
+
+	// MultiValue manages several records of the host, one per IP, as
+	// needed by active-active mode.
+	MultiValue bool
+
 	PrimaryIP            string
 	SecondaryIP          string
 	PrimaryHealthChecker HealthChecker
+
+	// SecondaryHealthChecker is optional, without it the secondary is
+	// assumed to be healthy.
+	SecondaryHealthChecker HealthChecker
 }
 
-func eval(o Gslb) error {
-	// Check primary health
-	healthy, err := o.CheckPrimaryHealth()
-	if err != nil {
-		return fmt.Errorf("checking primary health: %w", err)
+// TargetList returns the configured targets in order of priority.
+func (c GslbConfig) TargetList() []Target {
+	if len(c.Targets) > 0 {
+		return c.Targets
+	}
+
+	return []Target{
+		{Name: "primary", IP: c.PrimaryIP, HealthChecker: c.PrimaryHealthChecker},
+		{Name: "secondary", IP: c.SecondaryIP, HealthChecker: c.SecondaryHealthChecker},
+	}
+}
+
+// BothDownPolicy decides on the record when no target is available, the
+// one the record points to has failed and all others are unhealthy.
+type BothDownPolicy string
+
+const (
+	// BothDownKeep leaves the record as it is.
+	BothDownKeep BothDownPolicy = "keep"
+	// BothDownPrimary points the record to the first target.
+	BothDownPrimary BothDownPolicy = "primary"
+	// BothDownDisable disables the record, failing closed.
+	BothDownDisable BothDownPolicy = "disable"
+)
+
+// ParseBothDownPolicy parses "keep", "primary" or "disable", an empty string
+// keeps the record.
+func ParseBothDownPolicy(s string) (BothDownPolicy, error) {
+	switch p := BothDownPolicy(s); p {
+	case "":
+		return BothDownKeep, nil
+	case BothDownKeep, BothDownPrimary, BothDownDisable:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported both down policy %q", s)
+	}
+}
+
+// Mode selects how many targets the record points to.
+type Mode string
+
+const (
+	// ModeFailover points the record to the first available target.
+	ModeFailover Mode = "failover"
+	// ModeActiveActive points the record to all available targets, so that
+	// DNS round-robins across them.
+	ModeActiveActive Mode = "active-active"
+)
+
+// ParseMode parses "failover" or "active-active", an empty string fails
+// over.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case "":
+		return ModeFailover, nil
+	case ModeFailover, ModeActiveActive:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unsupported mode %q", s)
+	}
+}
+
+// SwitchPolicy configures when the GSLB record is switched. Zero values
+// switch on the first evaluation.
+type SwitchPolicy struct {
+	Mode Mode
+
+	// Rise is the number of consecutive healthy evaluations of a target
+	// before failing back to it.
+	Rise int
+
+	// Fall is the number of consecutive unhealthy evaluations of the target
+	// the record points to before failing over.
+	Fall int
+
+	// MinDwell is the minimum time between two switches of the record.
+	MinDwell time.Duration
+
+	Damping FlapDamping
+
+	// BothDown applies when no target is available, traffic is never moved
+	// to a target known to be down. In active-active mode, keeping the
+	// record falls back to the last healthy target.
+	BothDown BothDownPolicy
+}
+
+// targetState keeps the consecutive evaluations and flap penalty of a
+// target.
+type targetState struct {
+	rise    int
+	fall    int
+	damping *damping
+
+	// healthy is the time of the last healthy evaluation in active-active
+	// mode.
+	healthy time.Time
+}
+
+// count records an evaluation, a single one of the other kind resets the
+// counter.
+func (t *targetState) count(healthy bool) {
+	if healthy {
+		t.rise++
+		t.fall = 0
+	} else {
+		t.fall++
+		t.rise = 0
+	}
+}
+
+// switcher decides on the GSLB record of a host, keeping the state of its
+// targets between runs.
+type switcher struct {
+	o       Gslb
+	multi   MultiGslb
+	policy  SwitchPolicy
+	targets []Target
+	states  []targetState
+
+	lastSwitch time.Time
+	now        func() time.Time
+}
+
+func newSwitcher(o Gslb, policy SwitchPolicy) *switcher {
+	policy.Rise = max(policy.Rise, 1)
+	policy.Fall = max(policy.Fall, 1)
+
+	s := &switcher{
+		o:       o,
+		policy:  policy,
+		targets: o.Targets(),
+		now:     time.Now,
+	}
+
+	// Only needed in active-active mode
+	s.multi, _ = o.(MultiGslb)
+
+	s.states = make([]targetState, len(s.targets))
+	for i := range s.states {
+		s.states[i].damping = newDamping(policy.Damping)
+	}
+
+	return s
+}
+
+// eval checks the targets in order of priority and points the record to the
+// first available one. Targets before the current one need Rise healthy
+// evaluations, the current one stays until it failed Fall times and targets
+// after it are failed over to if they are healthy. If none of them is, a
+// healthy target before the current one is switched to regardless of Rise
+// and flap damping.
+func (s *switcher) eval(ctx context.Context) error {
+	if s.policy.Mode == ModeActiveActive {
+		return s.evalActive(ctx)
 	}
 
 	// Get GSLB record state
-	rec, err := o.GetCurrentIP()
+	rec, err := s.o.GetCurrentIP(ctx)
 	if err != nil {
 		return fmt.Errorf("getting GSLB record IP: %w", err)
 	}
 
-	if healthy && !compareIPs(rec, o.PrimaryIP()) {
-		// Switch to primary IP if primary is healthy
-		if err := o.SwitchToPrimaryIP(); err != nil {
-			return fmt.Errorf("updating GSLB record to primary IP: %w", err)
+	cur := s.index(rec)
+
+	results, err := s.checkAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Degraded targets still serve traffic and are no reason to fail over
+	healthy := make([]bool, len(results))
+	for i, res := range results {
+		healthy[i] = res.Healthy()
+		s.states[i].count(healthy[i])
+	}
+
+	// First healthy target held back by rise or flap damping
+	heldBack := -1
+
+	for i, t := range s.targets {
+		st := &s.states[i]
+
+		switch {
+		case i < cur && healthy[i]:
+			if st.rise < s.policy.Rise {
+				log.Printf("Target %s healthy (rise %d/%d), keeping GSLB record on IP: %s", t.Name, st.rise, s.policy.Rise, rec)
+			} else if st.damping.suppressed(s.now()) {
+				log.Printf("Flap damping holding back failback to %s IP (penalty %.2f, reuse at %.2f): %s",
+					t.Name, st.damping.current(s.now()), st.damping.reuse(), t.IP)
+			} else {
+				return s.switchTo(ctx, cur, i, fmt.Sprintf("rise %d/%d", st.rise, s.policy.Rise))
+			}
+
+			if heldBack < 0 {
+				heldBack = i
+			}
+		case i == cur:
+			if st.fall >= s.policy.Fall {
+				continue
+			}
+
+			if !healthy[i] {
+				log.Printf("Target %s unhealthy (fall %d/%d), keeping GSLB record on IP: %s", t.Name, st.fall, s.policy.Fall, rec)
+			}
+
+			return nil
+		case i > cur && healthy[i]:
+			if cur < 0 {
+				return s.switchTo(ctx, cur, i, "record not on a healthy target")
+			}
+
+			return s.switchTo(ctx, cur, i, fmt.Sprintf("%s fall %d/%d", s.targets[cur].Name, s.states[cur].fall, s.policy.Fall))
+		}
+	}
+
+	// Holding back a recovering target only makes sense while the current
+	// one still serves
+	if heldBack >= 0 {
+		return s.switchTo(ctx, cur, heldBack, fmt.Sprintf("%s fall %d/%d, ignoring rise and flap damping", s.targets[cur].Name, s.states[cur].fall, s.policy.Fall))
+	}
+
+	return s.bothDown(ctx, rec, cur)
+}
+
+// checkAll checks the targets concurrently, each one within the check
+// timeout of the deadline of ctx. Checked one after the other, a few timing
+// out targets would use up the deadline before the others are reached. A
+// target whose check runs out of time is unhealthy, so it cannot hold up the
+// decision on the others.
+func (s *switcher) checkAll(ctx context.Context) ([]Result, error) {
+	checkCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, CheckTimeout(time.Until(deadline)))
+		defer cancel()
+	}
+
+	results := make([]Result, len(s.targets))
+	errs := make([]error, len(s.targets))
+
+	var wg sync.WaitGroup
+	for i, t := range s.targets {
+		wg.Go(func() {
+			results[i], errs[i] = s.o.CheckHealth(checkCtx, t)
+		})
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		switch {
+		case err == nil:
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+			log.Printf("Target %s check timed out, treating it as unhealthy", s.targets[i].Name)
+			results[i] = Result{State: StateUnhealthy, Class: ErrorTimeout, Status: "check timed out"}
+		default:
+			return nil, fmt.Errorf("checking %s health: %w", s.targets[i].Name, err)
+		}
+	}
+
+	return results, nil
+}
+
+// CheckTimeout returns how long the health checks of an evaluation taking
+// interval may run, the rest is left to update the record.
+func CheckTimeout(interval time.Duration) time.Duration {
+	return interval * 3 / 4
+}
+
+// index returns the position of ip in the targets, -1 if the record is
+// disabled or points elsewhere.
+func (s *switcher) index(ip string) int {
+	for i, t := range s.targets {
+		if SameIP(ip, t.IP) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// switchTo points the record from target cur to target i. Failing over from
+// a target counts as a flap of it.
+func (s *switcher) switchTo(ctx context.Context, cur, i int, reason string) error {
+	t := s.targets[i]
+
+	if s.dwelling(t.Name + " IP") {
+		return nil
+	}
+
+	if err := s.o.SwitchToIP(ctx, t.IP); err != nil {
+		return fmt.Errorf("updating GSLB record to %s IP: %w", t.Name, err)
+	}
+
+	s.lastSwitch = s.now()
+
+	if cur >= 0 && i > cur {
+		s.states[cur].damping.flap(s.lastSwitch)
+	}
+
+	log.Printf("Switched GSLB record to %s IP (%s): %s", t.Name, reason, t.IP)
+
+	return nil
+}
+
+// bothDown applies the both down policy.
+func (s *switcher) bothDown(ctx context.Context, rec string, cur int) error {
+	switch s.policy.BothDown {
+	case BothDownPrimary:
+		t := s.targets[0]
+		if cur == 0 || s.dwelling(t.Name+" IP") {
+			return nil
+		}
+
+		if err := s.o.SwitchToIP(ctx, t.IP); err != nil {
+			return fmt.Errorf("updating GSLB record to %s IP: %w", t.Name, err)
 		}
 
-		log.Println("Switched GSLB record to primary IP:", o.PrimaryIP())
-	} else if !healthy && !compareIPs(rec, o.SecondaryIP()) {
-		// Switch to secondary IP if primary is not healthy
-		if err := o.SwitchToSecondaryIP(); err != nil {
-			return fmt.Errorf("updating GSLB record to secondary IP: %w", err)
+		s.lastSwitch = s.now()
+
+		log.Printf("No target available, switched GSLB record to %s IP: %s", t.Name, t.IP)
+	case BothDownDisable:
+		if rec == "" || s.dwelling("disabled record") {
+			return nil
+		}
+
+		if err := s.o.DisableRecord(ctx); err != nil {
+			return fmt.Errorf("disabling GSLB record: %w", err)
 		}
 
-		log.Println("Switched GSLB record to secondary IP:", o.SecondaryIP())
+		s.lastSwitch = s.now()
+
+		log.Println("No target available, disabled GSLB record")
+	default:
+		log.Println("No target available, keeping GSLB record on IP:", rec)
 	}
 
 	return nil
 }
 
-func compareIPs(ip1, ip2 string) bool {
+// dwelling reports whether a switch to target is held back, because the
+// last switch was less than the minimum dwell time ago.
+func (s *switcher) dwelling(target string) bool {
+	if s.lastSwitch.IsZero() {
+		return false
+	}
+
+	since := s.now().Sub(s.lastSwitch)
+	if since >= s.policy.MinDwell {
+		return false
+	}
+
+	log.Printf("Minimum dwell time holding back switch to %s (last switch %s ago, minimum %s)",
+		target, since.Round(time.Second), s.policy.MinDwell)
+
+	return true
+}
+
+// SameIP reports whether both strings are valid IP addresses and equal,
+// regardless of their notation.
+func SameIP(ip1, ip2 string) bool {
 	// Parse IP addresses
 	addr1 := net.ParseIP(ip1)
 	addr2 := net.ParseIP(ip2)
@@ -72,12 +441,27 @@ func compareIPs(ip1, ip2 string) bool {
 	return addr1.Equal(addr2)
 }
 
-func Run(ctx context.Context, o Gslb, interval time.Duration) error {
+func Run(ctx context.Context, o Gslb, interval time.Duration, policy SwitchPolicy) error {
+	s := newSwitcher(o, policy)
+
+	if policy.Mode == ModeActiveActive && s.multi == nil {
+		return errors.New("provider does not support active-active mode")
+	}
+
+	if err := policy.Damping.Validate(); err != nil {
+		return fmt.Errorf("invalid flap damping: %w", err)
+	}
+
 	for {
 		select {
 		case <-time.After(interval):
-			// Perform the health check and update the GSLB record
-			if err := eval(o); err != nil {
+			// Perform the health check and update the GSLB record, an
+			// evaluation must not run into the next one.
+			evalCtx, cancel := context.WithTimeout(ctx, interval)
+			err := s.eval(evalCtx)
+			cancel()
+
+			if err != nil && ctx.Err() == nil {
 				log.Println("Error during GSLB evaluation:", err)
 			}
 		case <-ctx.Done():
@@ -1,12 +1,21 @@
 package gslb
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
 
 type mockGslb struct {
 	primaryIPval   string
 	secondaryIPval string
 	IsPrimaryUp    bool
+	IsSecondaryUp  bool
 	currentIP      string
+
+	// delay is the duration of every health check, e.g. to time out.
+	delay time.Duration
 }
 
 func newMockGslb() *mockGslb {
@@ -14,15 +23,41 @@ func newMockGslb() *mockGslb {
 		primaryIPval:   "10.0.1.1",
 		secondaryIPval: "20.0.2.2",
 		IsPrimaryUp:    true,
+		IsSecondaryUp:  true,
 		currentIP:      "10.0.1.1",
 	}
 }
 
-func (m *mockGslb) CheckPrimaryHealth() (bool, error) {
-	return m.IsPrimaryUp, nil
+func (m *mockGslb) Targets() []Target {
+	return []Target{
+		{Name: "primary", IP: m.primaryIPval},
+		{Name: "secondary", IP: m.secondaryIPval},
+	}
+}
+
+func (m *mockGslb) CheckHealth(ctx context.Context, t Target) (Result, error) {
+	if err := m.wait(ctx); err != nil {
+		return Result{}, err
+	}
+
+	if t.Name == "primary" {
+		return ResultOf(m.IsPrimaryUp, ""), nil
+	}
+
+	return ResultOf(m.IsSecondaryUp, ""), nil
+}
+
+// wait simulates the duration of a health check.
+func (m *mockGslb) wait(ctx context.Context) error {
+	select {
+	case <-time.After(m.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-func (m *mockGslb) GetCurrentIP() (string, error) {
+func (m *mockGslb) GetCurrentIP(ctx context.Context) (string, error) {
 	return m.currentIP, nil
 }
 
@@ -34,26 +69,59 @@ func (m *mockGslb) SecondaryIP() string {
 	return m.secondaryIPval
 }
 
-func (m *mockGslb) SwitchToPrimaryIP() error {
-	m.currentIP = m.PrimaryIP()
+func (m *mockGslb) SwitchToIP(ctx context.Context, ip string) error {
+	m.currentIP = ip
 	return nil
 }
 
-func (m *mockGslb) SwitchToSecondaryIP() error {
-	m.currentIP = m.SecondaryIP()
+func (m *mockGslb) DisableRecord(ctx context.Context) error {
+	m.currentIP = ""
 	return nil
 }
 
+// poolGslb has any number of targets, named by their IP.
+type poolGslb struct {
+	mockGslb
+	ips []string
+	up  map[string]bool
+
+	// hang holds the targets whose checks block until the context is done.
+	hang map[string]bool
+}
+
+func (p *poolGslb) Targets() []Target {
+	var targets []Target
+	for _, ip := range p.ips {
+		targets = append(targets, Target{Name: ip, IP: ip})
+	}
+
+	return targets
+}
+
+func (p *poolGslb) CheckHealth(ctx context.Context, t Target) (Result, error) {
+	if p.hang[t.IP] {
+		<-ctx.Done()
+		return Result{}, ctx.Err()
+	}
+
+	if err := p.wait(ctx); err != nil {
+		return Result{}, err
+	}
+
+	return ResultOf(p.up[t.IP], ""), nil
+}
+
 func TestGslbEval(t *testing.T) {
 	// Create mock GSLB
 	g := newMockGslb()
 	var _ Gslb = g // Ensure mockGslb implements Gslb interface
-	if err := eval(g); err != nil {
+	s := newSwitcher(g, SwitchPolicy{})
+	if err := s.eval(context.Background()); err != nil {
 		t.Fatalf("eval() failed: %v", err)
 	}
 
 	// Check initial state
-	curIP, err := g.GetCurrentIP()
+	curIP, err := g.GetCurrentIP(context.Background())
 	if err != nil {
 		t.Fatalf("GetCurrentIP() failed: %v", err)
 	}
@@ -63,11 +131,11 @@ func TestGslbEval(t *testing.T) {
 
 	// Simulate primary down
 	g.IsPrimaryUp = false
-	if err := eval(g); err != nil {
+	if err := s.eval(context.Background()); err != nil {
 		t.Fatalf("eval() failed: %v", err)
 	}
 
-	curIP, err = g.GetCurrentIP()
+	curIP, err = g.GetCurrentIP(context.Background())
 	if err != nil {
 		t.Fatalf("GetCurrentIP() failed: %v", err)
 	}
@@ -77,11 +145,11 @@ func TestGslbEval(t *testing.T) {
 
 	// Simulate primary up again
 	g.IsPrimaryUp = true
-	if err := eval(g); err != nil {
+	if err := s.eval(context.Background()); err != nil {
 		t.Fatalf("eval() failed: %v", err)
 	}
 
-	curIP, err = g.GetCurrentIP()
+	curIP, err = g.GetCurrentIP(context.Background())
 	if err != nil {
 		t.Fatalf("GetCurrentIP() failed: %v", err)
 	}
@@ -89,3 +157,422 @@ func TestGslbEval(t *testing.T) {
 		t.Fatalf("expected CurrentIP to be PrimaryIP (%s), got %s", g.PrimaryIP(), curIP)
 	}
 }
+
+func TestGslbEvalRiseFall(t *testing.T) {
+	g := newMockGslb()
+	s := newSwitcher(g, SwitchPolicy{Rise: 2, Fall: 3})
+
+	steps := []struct {
+		primaryUp bool
+		wantIP    string
+	}{
+		// A flapping primary does not cause a failover
+		{false, g.PrimaryIP()},
+		{false, g.PrimaryIP()},
+		{true, g.PrimaryIP()},
+		{false, g.PrimaryIP()},
+		{false, g.PrimaryIP()},
+		// Third consecutive unhealthy evaluation fails over
+		{false, g.SecondaryIP()},
+		// A single healthy evaluation does not fail back
+		{true, g.SecondaryIP()},
+		{false, g.SecondaryIP()},
+		{true, g.SecondaryIP()},
+		// Second consecutive healthy evaluation fails back
+		{true, g.PrimaryIP()},
+	}
+
+	for i, step := range steps {
+		g.IsPrimaryUp = step.primaryUp
+		if err := s.eval(context.Background()); err != nil {
+			t.Fatalf("step %d: eval() failed: %v", i, err)
+		}
+
+		if g.currentIP != step.wantIP {
+			t.Fatalf("step %d: expected CurrentIP %s, got %s", i, step.wantIP, g.currentIP)
+		}
+	}
+}
+
+func TestGslbEvalDwellAndDamping(t *testing.T) {
+	g := newMockGslb()
+	s := newSwitcher(g, SwitchPolicy{
+		MinDwell: 5 * time.Minute,
+		Damping:  FlapDamping{MaxFlaps: 1, HalfLife: 10 * time.Minute},
+	})
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	step := func(primaryUp bool, after time.Duration, wantIP string) {
+		t.Helper()
+
+		now = now.Add(after)
+		g.IsPrimaryUp = primaryUp
+		if err := s.eval(context.Background()); err != nil {
+			t.Fatalf("eval() failed: %v", err)
+		}
+
+		if g.currentIP != wantIP {
+			t.Fatalf("expected CurrentIP %s, got %s", wantIP, g.currentIP)
+		}
+	}
+
+	// The first switch is not held back
+	step(false, 0, g.SecondaryIP())
+	// Failback waits for the minimum dwell time
+	step(true, time.Minute, g.SecondaryIP())
+	step(true, 4*time.Minute, g.PrimaryIP())
+	// Failover waits for the minimum dwell time too
+	step(false, time.Minute, g.PrimaryIP())
+	step(false, 4*time.Minute, g.SecondaryIP())
+
+	// Penalty is now 1.5, failback is suppressed until it decays to 0.5
+	step(true, 5*time.Minute, g.SecondaryIP())
+	step(true, 10*time.Minute, g.SecondaryIP())
+	step(true, 10*time.Minute, g.PrimaryIP())
+}
+
+func TestGslbEvalHeldBackFailover(t *testing.T) {
+	type step struct {
+		primaryUp, secondaryUp bool
+		wantIP                 string
+	}
+
+	tests := []struct {
+		name   string
+		policy SwitchPolicy
+		steps  []step
+	}{
+		{
+			name:   "rise",
+			policy: SwitchPolicy{Rise: 3},
+			steps: []step{
+				{false, true, "20.0.2.2"},
+				{true, true, "20.0.2.2"},
+				// The primary has not risen yet, but the secondary failed
+				{true, false, "10.0.1.1"},
+			},
+		},
+		{
+			name:   "damping",
+			policy: SwitchPolicy{Damping: FlapDamping{MaxFlaps: 1, HalfLife: 10 * time.Minute}},
+			steps: []step{
+				{false, true, "20.0.2.2"},
+				{true, true, "10.0.1.1"},
+				{false, true, "20.0.2.2"},
+				{true, true, "20.0.2.2"},
+				// Failback is suppressed, but the secondary failed
+				{true, false, "10.0.1.1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newMockGslb()
+			s := newSwitcher(g, tt.policy)
+
+			now := time.Now()
+			s.now = func() time.Time { return now }
+
+			for i, step := range tt.steps {
+				g.IsPrimaryUp, g.IsSecondaryUp = step.primaryUp, step.secondaryUp
+				if err := s.eval(context.Background()); err != nil {
+					t.Fatalf("step %d: eval() failed: %v", i, err)
+				}
+
+				if g.currentIP != step.wantIP {
+					t.Fatalf("step %d: expected CurrentIP %s, got %s", i, step.wantIP, g.currentIP)
+				}
+			}
+		})
+	}
+}
+
+func TestGslbEvalBothDown(t *testing.T) {
+	tests := []struct {
+		policy BothDownPolicy
+		// Record while both are down, starting on the primary and on the
+		// secondary
+		wantFromPrimary   string
+		wantFromSecondary string
+	}{
+		{BothDownKeep, "10.0.1.1", "20.0.2.2"},
+		{BothDownPrimary, "10.0.1.1", "10.0.1.1"},
+		{BothDownDisable, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			for start, want := range map[string]string{"10.0.1.1": tt.wantFromPrimary, "20.0.2.2": tt.wantFromSecondary} {
+				g := newMockGslb()
+				g.currentIP = start
+				g.IsPrimaryUp = false
+				g.IsSecondaryUp = false
+
+				s := newSwitcher(g, SwitchPolicy{BothDown: tt.policy})
+				if err := s.eval(context.Background()); err != nil {
+					t.Fatalf("eval() failed: %v", err)
+				}
+
+				if g.currentIP != want {
+					t.Errorf("starting on %s: expected CurrentIP %q, got %q", start, want, g.currentIP)
+				}
+
+				// Recovery of the secondary moves traffic there, enabling
+				// a disabled record
+				g.IsSecondaryUp = true
+				if err := s.eval(context.Background()); err != nil {
+					t.Fatalf("eval() failed: %v", err)
+				}
+
+				if g.currentIP != g.SecondaryIP() {
+					t.Errorf("starting on %s: expected CurrentIP %s after secondary recovered, got %q", start, g.SecondaryIP(), g.currentIP)
+				}
+			}
+		})
+	}
+}
+
+func TestGslbEvalBothDownSlow(t *testing.T) {
+	g := newMockGslb()
+	g.IsPrimaryUp = false
+	g.IsSecondaryUp = false
+
+	// Both checks time out, together they take longer than an evaluation
+	g.delay = 100 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	s := newSwitcher(g, SwitchPolicy{BothDown: BothDownDisable})
+	if err := s.eval(ctx); err != nil {
+		t.Fatalf("eval() failed: %v", err)
+	}
+
+	if g.currentIP != "" {
+		t.Errorf("expected disabled record, got %q", g.currentIP)
+	}
+}
+
+func TestGslbEvalPool(t *testing.T) {
+	g := &poolGslb{
+		mockGslb: mockGslb{currentIP: "10.0.1.1"},
+		ips:      []string{"10.0.1.1", "10.0.2.1", "10.0.3.1"},
+		up:       map[string]bool{"10.0.1.1": true, "10.0.2.1": true, "10.0.3.1": true},
+	}
+	s := newSwitcher(g, SwitchPolicy{Rise: 2})
+
+	steps := []struct {
+		up     []bool
+		wantIP string
+	}{
+		// The first healthy target in order of priority is chosen
+		{[]bool{false, true, true}, "10.0.2.1"},
+		{[]bool{false, false, true}, "10.0.3.1"},
+		// Failback to a higher priority target needs Rise evaluations
+		{[]bool{false, true, true}, "10.0.3.1"},
+		{[]bool{false, true, true}, "10.0.2.1"},
+		{[]bool{true, true, true}, "10.0.2.1"},
+		{[]bool{true, true, true}, "10.0.1.1"},
+		// A failed target is skipped when failing over
+		{[]bool{false, false, true}, "10.0.3.1"},
+	}
+
+	for i, step := range steps {
+		for j, ip := range g.ips {
+			g.up[ip] = step.up[j]
+		}
+
+		if err := s.eval(context.Background()); err != nil {
+			t.Fatalf("step %d: eval() failed: %v", i, err)
+		}
+
+		if g.currentIP != step.wantIP {
+			t.Fatalf("step %d: expected CurrentIP %s, got %s", i, step.wantIP, g.currentIP)
+		}
+	}
+}
+
+func TestGslbEvalPoolHeldBackFailover(t *testing.T) {
+	tests := []struct {
+		name   string
+		up     []bool
+		wantIP string
+	}{
+		// The best target wins, neither has risen yet
+		{"all rising", []bool{true, true, false}, "10.0.1.1"},
+		{"second rising", []bool{false, true, false}, "10.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &poolGslb{
+				mockGslb: mockGslb{currentIP: "10.0.3.1"},
+				ips:      []string{"10.0.1.1", "10.0.2.1", "10.0.3.1"},
+				up:       map[string]bool{"10.0.1.1": true, "10.0.2.1": true, "10.0.3.1": true},
+			}
+			s := newSwitcher(g, SwitchPolicy{Rise: 3})
+
+			if err := s.eval(context.Background()); err != nil {
+				t.Fatalf("eval() failed: %v", err)
+			}
+
+			// The current target fails while the others are still rising
+			for j, ip := range g.ips {
+				g.up[ip] = tt.up[j]
+			}
+
+			if err := s.eval(context.Background()); err != nil {
+				t.Fatalf("eval() failed: %v", err)
+			}
+
+			if g.currentIP != tt.wantIP {
+				t.Errorf("expected CurrentIP %s, got %s", tt.wantIP, g.currentIP)
+			}
+		})
+	}
+}
+
+func TestGslbEvalPoolSlow(t *testing.T) {
+	g := &poolGslb{
+		mockGslb: mockGslb{currentIP: "10.0.1.1"},
+		ips:      []string{"10.0.1.1", "10.0.2.1", "10.0.3.1"},
+		up:       map[string]bool{"10.0.3.1": true},
+	}
+
+	// Every check takes most of the evaluation, the first two fail
+	g.delay = 100 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	s := newSwitcher(g, SwitchPolicy{})
+	if err := s.eval(ctx); err != nil {
+		t.Fatalf("eval() failed: %v", err)
+	}
+
+	if g.currentIP != "10.0.3.1" {
+		t.Errorf("expected CurrentIP 10.0.3.1, got %s", g.currentIP)
+	}
+}
+
+func TestGslbEvalHangingCheck(t *testing.T) {
+	g := &poolGslb{
+		mockGslb: mockGslb{currentIP: "10.0.1.1"},
+		ips:      []string{"10.0.1.1", "10.0.2.1"},
+		up:       map[string]bool{"10.0.2.1": true},
+		hang:     map[string]bool{"10.0.1.1": true},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// The hanging target is unhealthy instead of failing the evaluation
+	s := newSwitcher(g, SwitchPolicy{})
+	if err := s.eval(ctx); err != nil {
+		t.Fatalf("eval() failed: %v", err)
+	}
+
+	if g.currentIP != "10.0.2.1" {
+		t.Errorf("expected CurrentIP 10.0.2.1, got %s", g.currentIP)
+	}
+}
+
+func TestGslbConfigTargetList(t *testing.T) {
+	cfg := GslbConfig{PrimaryIP: "10.0.1.1", SecondaryIP: "20.0.2.2"}
+
+	targets := cfg.TargetList()
+	if len(targets) != 2 || targets[0].IP != cfg.PrimaryIP || targets[1].IP != cfg.SecondaryIP {
+		t.Errorf("expected primary and secondary targets, got %+v", targets)
+	}
+
+	cfg.Targets = []Target{{Name: "dc1", IP: "10.0.1.1"}}
+	if targets := cfg.TargetList(); len(targets) != 1 || targets[0].Name != "dc1" {
+		t.Errorf("expected configured targets, got %+v", targets)
+	}
+}
+
+func TestRunInvalidDamping(t *testing.T) {
+	policy := SwitchPolicy{Damping: FlapDamping{MaxFlaps: 3, HalfLife: time.Hour, MaxSuppress: time.Minute}}
+	if err := Run(context.Background(), newMockGslb(), 0, policy); err == nil {
+		t.Error("expected error for damping that can never suppress")
+	}
+}
+
+func TestParseBothDownPolicy(t *testing.T) {
+	if p, err := ParseBothDownPolicy(""); err != nil || p != BothDownKeep {
+		t.Errorf("expected keep by default, got %q, %v", p, err)
+	}
+
+	if p, err := ParseBothDownPolicy("disable"); err != nil || p != BothDownDisable {
+		t.Errorf("expected disable, got %q, %v", p, err)
+	}
+
+	if _, err := ParseBothDownPolicy("secondary"); err == nil {
+		t.Error("expected error for unsupported policy")
+	}
+}
+
+// blockingGslb blocks in CheckHealth until the context is done.
+type blockingGslb struct {
+	mockGslb
+	checks chan struct{}
+}
+
+func (b *blockingGslb) CheckHealth(ctx context.Context, t Target) (Result, error) {
+	select {
+	case b.checks <- struct{}{}:
+	default:
+	}
+
+	<-ctx.Done()
+	return Result{}, ctx.Err()
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	g := &blockingGslb{mockGslb: *newMockGslb(), checks: make(chan struct{}, 1)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- Run(ctx, g, 10*time.Millisecond, SwitchPolicy{})
+	}()
+
+	// Cancel while a health check is in progress
+	<-g.checks
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not return after cancel")
+	}
+
+	if g.currentIP != g.PrimaryIP() {
+		t.Errorf("expected CurrentIP to stay PrimaryIP (%s), got %s", g.PrimaryIP(), g.currentIP)
+	}
+}
+
+func TestSameIP(t *testing.T) {
+	tests := []struct {
+		ip1, ip2 string
+		want     bool
+	}{
+		{"10.0.0.1", "10.0.0.1", true},
+		{"10.0.0.1", "::ffff:10.0.0.1", true},
+		{"2001:db8::1", "2001:db8:0::1", true},
+		{"10.0.0.1", "10.0.0.2", false},
+		{"", "", false},
+		{"host", "host", false},
+	}
+
+	for _, tt := range tests {
+		if got := SameIP(tt.ip1, tt.ip2); got != tt.want {
+			t.Errorf("SameIP(%q, %q) = %v, want %v", tt.ip1, tt.ip2, got, tt.want)
+		}
+	}
+}
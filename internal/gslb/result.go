@@ -0,0 +1,132 @@
+package gslb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// State is the health state of a target.
+type State int
+
+const (
+	StateUnhealthy State = iota
+	// StateDegraded targets still serve traffic, but not as they should,
+	// e.g. because they respond slowly.
+	StateDegraded
+	StateHealthy
+	// StateUnknown means the health could not be determined, it is treated
+	// like unhealthy.
+	StateUnknown
+)
+
+func (s State) String() string {
+	switch s {
+	case StateHealthy:
+		return "healthy"
+	case StateDegraded:
+		return "degraded"
+	case StateUnhealthy:
+		return "unhealthy"
+	case StateUnknown:
+		return "unknown"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// ErrorClass tells why a target is not healthy, so a timeout can be told
+// apart from a TLS error or an unexpected response.
+type ErrorClass string
+
+const (
+	ErrorNone       ErrorClass = ""
+	ErrorTimeout    ErrorClass = "timeout"
+	ErrorConnection ErrorClass = "connection"
+	ErrorTLS        ErrorClass = "tls"
+	ErrorStatus     ErrorClass = "status"
+	ErrorResponse   ErrorClass = "response"
+	ErrorLatency    ErrorClass = "latency"
+	ErrorUnknown    ErrorClass = "unknown"
+)
+
+// Result is the outcome of a health check.
+type Result struct {
+	State State
+	Class ErrorClass
+
+	// Status is a human readable description of the outcome.
+	Status string
+
+	// Latency is the duration of the last attempt, Attempts the number of
+	// attempts made.
+	Latency  time.Duration
+	Attempts int
+
+	// Details holds checker specific information, e.g. the HTTP status code.
+	Details map[string]string
+}
+
+// Healthy reports whether the target can serve traffic, which includes
+// degraded targets.
+func (r Result) Healthy() bool {
+	return r.State == StateHealthy || r.State == StateDegraded
+}
+
+// ResultOf builds the result of a check that only reports healthy or not.
+func ResultOf(healthy bool, status string) Result {
+	if healthy {
+		return Result{State: StateHealthy, Status: status}
+	}
+
+	return Result{State: StateUnhealthy, Class: ErrorUnknown, Status: status}
+}
+
+// LegacyHealthChecker is the original HealthChecker signature, reporting
+// only whether the target is healthy and a free-form status.
+type LegacyHealthChecker interface {
+	CheckHealth() (bool, string, error)
+}
+
+// Legacy adapts a LegacyHealthChecker to HealthChecker, for checkers outside
+// this module. As retries are not visible, the result has no attempt count,
+// its latency covers the whole check and unhealthy results have the error
+// class ErrorUnknown. The check cannot be cancelled, if ctx is done first it
+// is left to finish in the background.
+func Legacy(c LegacyHealthChecker) HealthChecker {
+	return legacyChecker{c}
+}
+
+type legacyChecker struct {
+	LegacyHealthChecker
+}
+
+type legacyOutcome struct {
+	healthy bool
+	status  string
+	err     error
+}
+
+func (l legacyChecker) Check(ctx context.Context) (Result, error) {
+	start := time.Now()
+
+	done := make(chan legacyOutcome, 1)
+	go func() {
+		healthy, status, err := l.CheckHealth()
+		done <- legacyOutcome{healthy, status, err}
+	}()
+
+	select {
+	case out := <-done:
+		if out.err != nil {
+			return Result{}, out.err
+		}
+
+		res := ResultOf(out.healthy, out.status)
+		res.Latency = time.Since(start)
+
+		return res, nil
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	}
+}
@@ -0,0 +1,88 @@
+package gslb
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type legacyMock struct {
+	healthy bool
+	status  string
+	err     error
+	block   chan struct{}
+}
+
+func (m legacyMock) CheckHealth() (bool, string, error) {
+	if m.block != nil {
+		<-m.block
+	}
+
+	return m.healthy, m.status, m.err
+}
+
+func TestLegacy(t *testing.T) {
+	tests := []struct {
+		name      string
+		checker   legacyMock
+		wantState State
+		wantClass ErrorClass
+		wantErr   bool
+	}{
+		{"healthy", legacyMock{healthy: true, status: "ok"}, StateHealthy, ErrorNone, false},
+		{"unhealthy", legacyMock{status: "refused"}, StateUnhealthy, ErrorUnknown, false},
+		{"error", legacyMock{err: errors.New("bad config")}, StateUnhealthy, ErrorNone, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Legacy(tt.checker).Check(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if res.State != tt.wantState {
+				t.Errorf("Check() state = %v, want %v", res.State, tt.wantState)
+			}
+			if res.Class != tt.wantClass {
+				t.Errorf("Check() class = %q, want %q", res.Class, tt.wantClass)
+			}
+			if res.Status != tt.checker.status {
+				t.Errorf("Check() status = %q, want %q", res.Status, tt.checker.status)
+			}
+		})
+	}
+}
+
+func TestLegacy_ContextDone(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Legacy(legacyMock{block: block}).Check(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Check() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestResult_Healthy(t *testing.T) {
+	tests := []struct {
+		state State
+		want  bool
+	}{
+		{StateHealthy, true},
+		{StateDegraded, true},
+		{StateUnhealthy, false},
+		{StateUnknown, false},
+	}
+
+	for _, tt := range tests {
+		if got := (Result{State: tt.state}).Healthy(); got != tt.want {
+			t.Errorf("Result{State: %v}.Healthy() = %v, want %v", tt.state, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package opnsense
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// hostOverride is a Host Override record of the GSLB host.
+type hostOverride struct {
+	uuid string
+	*unboundGetHostOverrideResponse
+}
+
+// getHostOverrides returns all A and AAAA records of the GSLB host.
+func (o *OpnSenseGslb) getHostOverrides(ctx context.Context) ([]hostOverride, error) {
+	uuids, err := o.getGslbRecordUUIDs(ctx, o.cfg.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make([]hostOverride, 0, len(uuids))
+	for _, uuid := range uuids {
+		override, err := o.getHostOverride(ctx, uuid)
+		if err != nil {
+			return nil, fmt.Errorf("getting host override %s: %w", uuid, err)
+		}
+
+		overrides = append(overrides, hostOverride{uuid: uuid, unboundGetHostOverrideResponse: override})
+	}
+
+	return overrides, nil
+}
+
+// GetCurrentIPs implements gslb.MultiGslb.
+func (o *OpnSenseGslb) GetCurrentIPs(ctx context.Context) ([]string, error) {
+	overrides, err := o.getHostOverrides(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting host overrides: %w", err)
+	}
+
+	var ips []string
+	for _, override := range overrides {
+		if override.Host.Enabled != "0" && override.Host.Server != "" {
+			ips = append(ips, override.Host.Server)
+		}
+	}
+
+	return ips, nil
+}
+
+// SetIPs implements gslb.MultiGslb. Records already pointing to one of ips
+// are kept, the others are reused for the remaining IPs and deleted if
+// unused. Records are added as copies of the first one, which is disabled
+// instead of deleted if ips is empty. If an earlier call failed before
+// reconfiguring Unbound, it is reconfigured even without changes.
+func (o *OpnSenseGslb) SetIPs(ctx context.Context, ips []string) error {
+	overrides, err := o.getHostOverrides(ctx)
+	if err != nil {
+		return fmt.Errorf("getting host overrides: %w", err)
+	}
+
+	pending := slices.Clone(ips)
+	var unused []hostOverride
+
+	for _, override := range overrides {
+		i := slices.IndexFunc(pending, func(ip string) bool {
+			return gslb.SameIP(ip, override.Host.Server)
+		})
+
+		if i >= 0 && override.Host.Enabled != "0" {
+			pending = slices.Delete(pending, i, i+1)
+			continue
+		}
+
+		unused = append(unused, override)
+	}
+
+	for _, ip := range pending {
+		// A failed request may still have been saved
+		o.unapplied = true
+
+		if len(unused) > 0 {
+			if err := o.setHostOverride(ctx, unused[0].uuid, unused[0].unboundGetHostOverrideResponse, "1", rrType(ip), ip); err != nil {
+				return err
+			}
+
+			unused = unused[1:]
+		} else if err := o.addHostOverride(ctx, overrides[0].unboundGetHostOverrideResponse, ip); err != nil {
+			return err
+		}
+	}
+
+	for i, override := range unused {
+		// Keep a disabled record, so the host is not lost
+		keep := len(ips) == 0 && i == 0
+		if keep && override.Host.Enabled == "0" {
+			continue
+		}
+
+		o.unapplied = true
+
+		if keep {
+			if err := o.setHostOverride(ctx, override.uuid, override.unboundGetHostOverrideResponse, "0", rrType(override.Host.Server), override.Host.Server); err != nil {
+				return err
+			}
+		} else if err := o.delHostOverride(ctx, override.uuid); err != nil {
+			return err
+		}
+	}
+
+	if !o.unapplied {
+		return nil
+	}
+
+	// Restart Unbound service to apply changes
+	if err := o.restartUnboundService(ctx); err != nil {
+		return fmt.Errorf("restarting Unbound service: %w", err)
+	}
+
+	o.unapplied = false
+
+	return nil
+}
+
+type unboundAddHostOverrideResponse struct {
+	Result string `json:"result"`
+	UUID   string `json:"uuid"`
+}
+
+// addHostOverride adds a record for ip with the settings of override,
+// without applying the change.
+func (o *OpnSenseGslb) addHostOverride(ctx context.Context, override *unboundGetHostOverrideResponse, ip string) error {
+	payload, err := hostOverridePayload(override, "1", rrType(ip), ip)
+	if err != nil {
+		return err
+	}
+
+	resp, err := o.doRequest(ctx, http.MethodPost, "/api/unbound/settings/addHostOverride", payload)
+	if err != nil {
+		return fmt.Errorf("addHostOverride request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("addHostOverride request failed: %s", resp.Status)
+	}
+
+	// Decode response
+	var addResp unboundAddHostOverrideResponse
+	if err := json.NewDecoder(resp.Body).Decode(&addResp); err != nil {
+		return fmt.Errorf("decoding addHostOverride response: %w", err)
+	}
+
+	if addResp.Result != "saved" {
+		return fmt.Errorf("addHostOverride request failed: unexpected result %s", addResp.Result)
+	}
+
+	return nil
+}
+
+type unboundDelHostOverrideResponse struct {
+	Result string `json:"result"`
+}
+
+// delHostOverride deletes the record uuid, without applying the change.
+func (o *OpnSenseGslb) delHostOverride(ctx context.Context, uuid string) error {
+	resp, err := o.doRequest(ctx, http.MethodPost, "/api/unbound/settings/delHostOverride/"+uuid, nil)
+	if err != nil {
+		return fmt.Errorf("delHostOverride request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("delHostOverride request failed: %s", resp.Status)
+	}
+
+	// Decode response
+	var delResp unboundDelHostOverrideResponse
+	if err := json.NewDecoder(resp.Body).Decode(&delResp); err != nil {
+		return fmt.Errorf("decoding delHostOverride response: %w", err)
+	}
+
+	if delResp.Result != "deleted" {
+		return fmt.Errorf("delHostOverride request failed: unexpected result %s", delResp.Result)
+	}
+
+	return nil
+}
+
+// rrType returns the resource record type of ip.
+func rrType(ip string) string {
+	if addr := net.ParseIP(ip); addr != nil && addr.To4() == nil {
+		return "AAAA"
+	}
+
+	return "A"
+}
@@ -0,0 +1,327 @@
+package opnsense
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/microfast-ch/gslb-switcher/internal/gslb"
+)
+
+// fakeUnbound keeps Host Override records like the OpnSense API.
+type fakeUnbound struct {
+	t           *testing.T
+	records     map[string]*unboundSetHostOverrideRequest
+	nextUUID    int
+	reconfigure int
+
+	// fail makes the next request with this path fail after processing it
+	fail string
+}
+
+func newFakeUnbound(t *testing.T, ips ...string) *fakeUnbound {
+	f := &fakeUnbound{t: t, records: map[string]*unboundSetHostOverrideRequest{}}
+	for _, ip := range ips {
+		rec := &unboundSetHostOverrideRequest{}
+		rec.Host.Enabled = "1"
+		rec.Host.Hostname = "api"
+		rec.Host.Domain = "example.com"
+		rec.Host.RR = rrType(ip)
+		rec.Host.TTL = "60"
+		rec.Host.Server = ip
+		f.add(rec)
+	}
+
+	return f
+}
+
+func (f *fakeUnbound) add(rec *unboundSetHostOverrideRequest) string {
+	f.nextUUID++
+	uuid := fmt.Sprintf("uuid-%d", f.nextUUID)
+	f.records[uuid] = rec
+
+	return uuid
+}
+
+// enabled returns the sorted IPs of enabled records.
+func (f *fakeUnbound) enabled() string {
+	var ips []string
+	for _, rec := range f.records {
+		if rec.Host.Enabled == "1" {
+			ips = append(ips, rec.Host.Server)
+		}
+	}
+
+	slices.Sort(ips)
+
+	return strings.Join(ips, ",")
+}
+
+func (f *fakeUnbound) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.fail != "" && strings.Contains(r.URL.Path, f.fail) {
+		f.fail = ""
+		f.serve(httptest.NewRecorder(), r)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+
+		return
+	}
+
+	f.serve(w, r)
+}
+
+func (f *fakeUnbound) serve(w http.ResponseWriter, r *http.Request) {
+	uuid := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/searchHostOverride/"):
+		var req unboundSearchHostOverrideRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			f.t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		resp := unboundSearchHostOverrideResponse{Total: len(f.records)}
+		for _, id := range slices.Sorted(maps.Keys(f.records)) {
+			rec := f.records[id]
+			resp.Rows = append(resp.Rows, unboundSearchHostOverrideRow{id, rec.Host.Hostname, rec.Host.Domain, rec.Host.RR + " (address)"})
+		}
+
+		// Pages start at 1
+		start := min((req.Current-1)*req.RowCount, len(resp.Rows))
+		resp.Rows = resp.Rows[start:min(start+req.RowCount, len(resp.Rows))]
+		json.NewEncoder(w).Encode(resp) // nolint:errcheck
+	case strings.Contains(r.URL.Path, "/getHostOverride/"):
+		rec := f.records[uuid]
+		fmt.Fprintf(w, `{"host":{"enabled":%q,"hostname":%q,"domain":%q,"ttl":%q,"server":%q,"rr":{%q:{"selected":1}}}}`,
+			rec.Host.Enabled, rec.Host.Hostname, rec.Host.Domain, rec.Host.TTL, rec.Host.Server, rec.Host.RR)
+	case strings.Contains(r.URL.Path, "/setHostOverride/"), strings.HasSuffix(r.URL.Path, "/addHostOverride"):
+		var rec unboundSetHostOverrideRequest
+		if err := json.NewDecoder(r.Body).Decode(&rec); err != nil {
+			f.t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		if rec.Host.TTL != "60" {
+			f.t.Errorf("Expected TTL to be copied, got '%s'", rec.Host.TTL)
+		}
+
+		if strings.Contains(r.URL.Path, "/setHostOverride/") {
+			f.records[uuid] = &rec
+		} else {
+			uuid = f.add(&rec)
+		}
+		fmt.Fprintf(w, `{"result":"saved","uuid":%q}`, uuid)
+	case strings.Contains(r.URL.Path, "/delHostOverride/"):
+		delete(f.records, uuid)
+		w.Write([]byte(`{"result":"deleted"}`)) // nolint:errcheck
+	case strings.HasSuffix(r.URL.Path, "/reconfigure"):
+		f.reconfigure++
+		w.Write([]byte(`{"status":"ok"}`)) // nolint:errcheck
+	default:
+		f.t.Errorf("Unexpected URL path: %s", r.URL.Path)
+	}
+}
+
+func TestSetIPs(t *testing.T) {
+	fake := newFakeUnbound(t, "10.0.0.1")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	g, err := NewOpnSenseGslb(context.Background(), server.URL, "", gslb.GslbConfig{
+		Host:       "api.example.com",
+		MultiValue: true,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	o := g.(*OpnSenseGslb)
+
+	steps := []struct {
+		ips  []string
+		want string
+	}{
+		{[]string{"10.0.0.1", "10.0.0.2", "2001:db8::1"}, "10.0.0.1,10.0.0.2,2001:db8::1"},
+		// Records of IPv4 addresses are reused for IPv6 addresses
+		{[]string{"2001:db8::2"}, "2001:db8::2"},
+		{[]string{"10.0.0.2"}, "10.0.0.2"},
+		{[]string{"10.0.0.3", "10.0.0.2"}, "10.0.0.2,10.0.0.3"},
+		// The last record is disabled instead of deleted
+		{nil, ""},
+		{[]string{"10.0.0.1"}, "10.0.0.1"},
+	}
+
+	for i, step := range steps {
+		if err := o.SetIPs(context.Background(), step.ips); err != nil {
+			t.Fatalf("step %d: Expected no error, got: %v", i, err)
+		}
+
+		if got := fake.enabled(); got != step.want {
+			t.Fatalf("step %d: Expected IPs %s, got %s", i, step.want, got)
+		}
+
+		ips, err := o.GetCurrentIPs(context.Background())
+		if err != nil {
+			t.Fatalf("step %d: Expected no error, got: %v", i, err)
+		}
+
+		if len(ips) != len(step.ips) {
+			t.Errorf("step %d: Expected current IPs %v, got %v", i, step.ips, ips)
+		}
+
+		for _, rec := range fake.records {
+			if rec.Host.Server != "" && rec.Host.RR != rrType(rec.Host.Server) {
+				t.Errorf("step %d: Expected %s record for '%s', got %s", i, rrType(rec.Host.Server), rec.Host.Server, rec.Host.RR)
+			}
+		}
+
+		if len(fake.records) != max(len(step.ips), 1) {
+			t.Errorf("step %d: Expected %d records, got %d", i, max(len(step.ips), 1), len(fake.records))
+		}
+	}
+
+	// Unchanged IPs do not reconfigure Unbound
+	reconfigure := fake.reconfigure
+	if err := o.SetIPs(context.Background(), []string{"10.0.0.1"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if fake.reconfigure != reconfigure {
+		t.Errorf("Expected no reconfigure, got %d", fake.reconfigure-reconfigure)
+	}
+}
+
+func TestSetIPs_Failure(t *testing.T) {
+	tests := []struct {
+		name string
+		fail string
+		ips  []string
+	}{
+		{"reconfigure", "/reconfigure", []string{"10.0.0.3"}},
+		{"delete", "/delHostOverride/", []string{"10.0.0.1"}},
+		{"add", "/addHostOverride", []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeUnbound(t, "10.0.0.1", "10.0.0.2")
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			g, err := NewOpnSenseGslb(context.Background(), server.URL, "", gslb.GslbConfig{
+				Host:       "api.example.com",
+				MultiValue: true,
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			o := g.(*OpnSenseGslb)
+
+			// The change is saved, but Unbound is not reconfigured
+			fake.fail = tt.fail
+			if err := o.SetIPs(context.Background(), tt.ips); err == nil {
+				t.Fatal("Expected error")
+			}
+
+			reconfigure := fake.reconfigure
+			if err := o.SetIPs(context.Background(), tt.ips); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if fake.reconfigure == reconfigure {
+				t.Error("Expected pending change to reconfigure Unbound")
+			}
+
+			// Once applied, unchanged IPs do not reconfigure again
+			reconfigure = fake.reconfigure
+			if err := o.SetIPs(context.Background(), tt.ips); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if fake.reconfigure != reconfigure {
+				t.Errorf("Expected no reconfigure, got %d", fake.reconfigure-reconfigure)
+			}
+		})
+	}
+}
+
+func TestNewOpnSenseGslb_MultiValue(t *testing.T) {
+	fake := newFakeUnbound(t, "10.0.0.1", "10.0.0.2")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	cfg := gslb.GslbConfig{Host: "api.example.com"}
+
+	// Several records are only accepted with multi-value records
+	if _, err := NewOpnSenseGslb(context.Background(), server.URL, "", cfg); err == nil {
+		t.Fatal("Expected error for multiple records")
+	}
+
+	cfg.MultiValue = true
+	g, err := NewOpnSenseGslb(context.Background(), server.URL, "", cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, ok := g.(gslb.MultiGslb); !ok {
+		t.Fatal("Expected OpnSenseGslb to implement gslb.MultiGslb")
+	}
+
+	// Single-value operations act on all records
+	if err := g.SwitchToIP(context.Background(), "10.0.0.3"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	ip, err := g.GetCurrentIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if ip != "10.0.0.3" || fake.enabled() != "10.0.0.3" {
+		t.Errorf("Expected record on '10.0.0.3', got '%s' (%s)", ip, fake.enabled())
+	}
+}
+
+func TestGetCurrentIPs_Paging(t *testing.T) {
+	var ips []string
+	for i := range searchPageSize + 5 {
+		ips = append(ips, fmt.Sprintf("10.0.%d.%d", i/250, i%250+1))
+	}
+
+	fake := newFakeUnbound(t, ips...)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	g, err := NewOpnSenseGslb(context.Background(), server.URL, "", gslb.GslbConfig{
+		Host:       "api.example.com",
+		MultiValue: true,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	o := g.(*OpnSenseGslb)
+
+	current, err := o.GetCurrentIPs(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(current) != len(ips) {
+		t.Fatalf("Expected %d IPs, got %d", len(ips), len(current))
+	}
+
+	// Records beyond the first page are reused instead of duplicated
+	if err := o.SetIPs(context.Background(), ips); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(fake.records) != len(ips) {
+		t.Errorf("Expected %d records, got %d", len(ips), len(fake.records))
+	}
+}
@@ -2,6 +2,7 @@ package opnsense
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -18,16 +19,30 @@ type OpnSenseGslb struct {
 	recordUUID string
 	epHost     string
 	epAuth     string
+
+	// unapplied is set while record changes may be saved but not applied
+	// by reconfiguring Unbound.
+	unapplied bool
 }
 
-func NewOpnSenseGslb(host, auth string, cfg gslb.GslbConfig) (gslb.Gslb, error) {
+func NewOpnSenseGslb(ctx context.Context, host, auth string, cfg gslb.GslbConfig) (gslb.Gslb, error) {
 	o := &OpnSenseGslb{
 		cfg:    cfg,
 		epHost: host,
 		epAuth: auth,
 	}
 
-	uuid, err := o.getGslbRecordUUID(cfg.Host)
+	// Multi-value records are looked up on every change, as they are added
+	// and deleted
+	if cfg.MultiValue {
+		if _, err := o.getHostOverrides(ctx); err != nil {
+			return nil, fmt.Errorf("getting GSLB records: %w", err)
+		}
+
+		return o, nil
+	}
+
+	uuid, err := o.getGslbRecordUUID(ctx, cfg.Host)
 	if err != nil {
 		return nil, fmt.Errorf("getting GSLB record: %w", err)
 	}
@@ -45,7 +60,7 @@ func (o *OpnSenseGslb) setAuthHeader(req *http.Request) {
 	}
 }
 
-func (o *OpnSenseGslb) doRequest(method, url string, body []byte) (*http.Response, error) {
+func (o *OpnSenseGslb) doRequest(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
 	client := &http.Client{
 		Timeout: 10 * time.Second,
 	}
@@ -54,9 +69,9 @@ func (o *OpnSenseGslb) doRequest(method, url string, body []byte) (*http.Respons
 	var err error
 
 	if body != nil {
-		req, err = http.NewRequest(method, o.epHost+url, bytes.NewReader(body))
+		req, err = http.NewRequestWithContext(ctx, method, o.epHost+url, bytes.NewReader(body))
 	} else {
-		req, err = http.NewRequest(method, o.epHost+url, nil)
+		req, err = http.NewRequestWithContext(ctx, method, o.epHost+url, nil)
 	}
 
 	if err != nil {
@@ -78,102 +93,154 @@ func (o *OpnSenseGslb) doRequest(method, url string, body []byte) (*http.Respons
 	return resp, nil
 }
 
-// PrimaryIP implements gslb.Gslb.
-func (o *OpnSenseGslb) PrimaryIP() string {
-	return o.cfg.PrimaryIP
+// Targets implements gslb.Gslb.
+func (o *OpnSenseGslb) Targets() []gslb.Target {
+	return o.cfg.TargetList()
 }
 
-// SecondaryIP implements gslb.Gslb.
-func (o *OpnSenseGslb) SecondaryIP() string {
-	return o.cfg.SecondaryIP
-}
+// searchPageSize is the number of records requested per searchHostOverride
+// page.
+const searchPageSize = 100
 
 type unboundSearchHostOverrideRequest struct {
+	Current      int    `json:"current"`
 	RowCount     int    `json:"rowCount"`
 	SearchPhrase string `json:"searchPhrase"`
 }
 
 type unboundSearchHostOverrideResponse struct {
-	Rows []struct {
-		UUID           string `json:"uuid"`
-		Hostname       string `json:"hostname"`
-		Domain         string `json:"domain"`
-		ResourceRecord string `json:"rr"`
-	} `json:"rows"`
+	Total int                            `json:"total"`
+	Rows  []unboundSearchHostOverrideRow `json:"rows"`
+}
+
+type unboundSearchHostOverrideRow struct {
+	UUID           string `json:"uuid"`
+	Hostname       string `json:"hostname"`
+	Domain         string `json:"domain"`
+	ResourceRecord string `json:"rr"`
+}
+
+func (o *OpnSenseGslb) getGslbRecordUUID(ctx context.Context, hostname string) (string, error) {
+	uuids, err := o.getGslbRecordUUIDs(ctx, hostname)
+	if err != nil {
+		return "", err
+	}
+
+	if len(uuids) > 1 {
+		return "", fmt.Errorf("multiple GSLB records found for hostname %s", hostname)
+	}
+
+	return uuids[0], nil
 }
 
-func (o *OpnSenseGslb) getGslbRecordUUID(hostname string) (string, error) {
+// getGslbRecordUUIDs returns the UUIDs of all A and AAAA records of
+// hostname.
+func (o *OpnSenseGslb) getGslbRecordUUIDs(ctx context.Context, hostname string) ([]string, error) {
 	// We need to extract only the host name from the potential FQDN as
 	// the API only searches in the host part.
 	hostpart := strings.SplitN(hostname, ".", 2)[0]
 
+	// Fetch all pages, the host may have more records than fit on one
+	var rows []unboundSearchHostOverrideRow
+	for page := 1; ; page++ {
+		searchResp, err := o.searchHostOverrides(ctx, hostpart, page)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, searchResp.Rows...)
+
+		if len(searchResp.Rows) == 0 || len(rows) >= searchResp.Total {
+			break
+		}
+	}
+
+	// Find records by hostname
+	var uuids []string
+	for _, row := range rows {
+		if !strings.HasPrefix(row.ResourceRecord, "A ") && !strings.HasPrefix(row.ResourceRecord, "AAAA ") {
+			// We only care about A and AAAA records
+			continue
+		}
+
+		if row.Hostname == hostname || row.Hostname+"."+row.Domain == hostname {
+			uuids = append(uuids, row.UUID)
+		}
+	}
+
+	if len(uuids) == 0 {
+		return nil, fmt.Errorf("no GSLB record found for hostname %s", hostname)
+	}
+
+	return uuids, nil
+}
+
+// searchHostOverrides returns a page of the Host Override records matching
+// phrase, starting with page 1.
+func (o *OpnSenseGslb) searchHostOverrides(ctx context.Context, phrase string, page int) (*unboundSearchHostOverrideResponse, error) {
 	// Prepare searchHostOverride request payload
 	reqPayload := &unboundSearchHostOverrideRequest{
-		RowCount:     10,
-		SearchPhrase: hostpart,
+		Current:      page,
+		RowCount:     searchPageSize,
+		SearchPhrase: phrase,
 	}
 
 	payload, err := json.Marshal(reqPayload)
 	if err != nil {
-		return "", fmt.Errorf("marshaling searchHostOverride request payload: %w", err)
+		return nil, fmt.Errorf("marshaling searchHostOverride request payload: %w", err)
 	}
 
 	// Search Host Override records
-	resp, err := o.doRequest(http.MethodPost, "/api/unbound/settings/searchHostOverride/", payload)
+	resp, err := o.doRequest(ctx, http.MethodPost, "/api/unbound/settings/searchHostOverride/", payload)
 	if err != nil {
-		return "", fmt.Errorf("searchHostOverride request: %w", err)
+		return nil, fmt.Errorf("searchHostOverride request: %w", err)
 	}
 
 	defer resp.Body.Close() //nolint:errcheck
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("searchHostOverride request failed: %s", resp.Status)
+		return nil, fmt.Errorf("searchHostOverride request failed: %s", resp.Status)
 	}
 
 	// Decode response
 	var searchResp unboundSearchHostOverrideResponse
 	if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
-		return "", fmt.Errorf("decoding searchHostOverride response: %w", err)
+		return nil, fmt.Errorf("decoding searchHostOverride response: %w", err)
 	}
 
-	// Find record by hostname
-	uuid := ""
-	for _, row := range searchResp.Rows {
-		if !strings.HasPrefix(row.ResourceRecord, "A ") && !strings.HasPrefix(row.ResourceRecord, "AAAA ") {
-			// We only care about A and AAAA records
-			continue
-		}
-
-		if row.Hostname == hostname || row.Hostname+"."+row.Domain == hostname {
-			// We found the record, check if it's the first one we found
-			if uuid != "" {
-				return "", fmt.Errorf("multiple GSLB records found for hostname %s", hostname)
-			}
-
-			uuid = row.UUID
-		}
-	}
+	return &searchResp, nil
+}
 
-	if uuid == "" {
-		return "", fmt.Errorf("no GSLB record found for hostname %s", hostname)
+// CheckHealth implements gslb.Gslb. Targets without health checker are
+// assumed to be healthy.
+func (o *OpnSenseGslb) CheckHealth(ctx context.Context, t gslb.Target) (gslb.Result, error) {
+	if t.HealthChecker == nil {
+		return gslb.Result{State: gslb.StateHealthy, Status: "not checked"}, nil
 	}
 
-	return uuid, nil
-}
-
-// CheckPrimaryHealth implements gslb.Gslb.
-func (o *OpnSenseGslb) CheckPrimaryHealth() (bool, error) {
 	// No special logic needed, pass directly to endpoint checker
-	ok, status, err := o.cfg.PrimaryHealthChecker.CheckHealth()
+	res, err := t.HealthChecker.Check(ctx)
 	if err != nil {
-		return false, fmt.Errorf("checking primary health: %w", err)
+		return gslb.Result{}, fmt.Errorf("checking %s health: %w", t.Name, err)
 	}
 
-	if !ok {
-		slog.Warn("Primary health check failed", "status", status)
+	if res.State != gslb.StateHealthy {
+		msg := "Health check failed"
+		if res.State == gslb.StateDegraded {
+			msg = "Health check degraded"
+		}
+
+		slog.Warn(msg,
+			"target", t.Name,
+			"state", res.State,
+			"class", res.Class,
+			"status", res.Status,
+			"latency", res.Latency,
+			"attempts", res.Attempts,
+		)
 	}
 
-	return ok, nil
+	return res, nil
 }
 
 type unboundGetHostOverrideResponse struct {
@@ -199,10 +266,9 @@ type unboundGetHostOverrideResponse struct {
 	} `json:"host"`
 }
 
-// GetCurrentIP implements gslb.Gslb.
-func (o *OpnSenseGslb) getHostOverride() (*unboundGetHostOverrideResponse, error) {
+func (o *OpnSenseGslb) getHostOverride(ctx context.Context, uuid string) (*unboundGetHostOverrideResponse, error) {
 	// Get Host Override record
-	resp, err := o.doRequest(http.MethodGet, "/api/unbound/settings/getHostOverride/"+o.recordUUID, nil)
+	resp, err := o.doRequest(ctx, http.MethodGet, "/api/unbound/settings/getHostOverride/"+uuid, nil)
 	if err != nil {
 		return nil, fmt.Errorf("getHostOverride request: %w", err)
 	}
@@ -221,13 +287,26 @@ func (o *OpnSenseGslb) getHostOverride() (*unboundGetHostOverrideResponse, error
 	return &getResp, nil
 }
 
-func (o *OpnSenseGslb) GetCurrentIP() (string, error) {
-	override, err := o.getHostOverride()
+// GetCurrentIP implements gslb.Gslb. A disabled record has no current IP,
+// multi-value records return their first IP.
+func (o *OpnSenseGslb) GetCurrentIP(ctx context.Context) (string, error) {
+	if o.cfg.MultiValue {
+		ips, err := o.GetCurrentIPs(ctx)
+		if err != nil || len(ips) == 0 {
+			return "", err
+		}
+
+		return ips[0], nil
+	}
+
+	override, err := o.getHostOverride(ctx, o.recordUUID)
 	if err != nil {
 		return "", fmt.Errorf("getting host override: %w", err)
 	}
 
 	switch {
+	case override.Host.Enabled == "0":
+		return "", nil
 	case override.Host.RR.A.Selected == 1 && override.Host.Server == "":
 		return "", fmt.Errorf("host override record has A record selected but no server IP set")
 	case override.Host.RR.AAAA.Selected == 1 && override.Host.Server == "":
@@ -259,8 +338,19 @@ type unboundSetHostOverrideResponse struct {
 	Result string `json:"result"`
 }
 
-func (o *OpnSenseGslb) switchToIP(ip string) error {
-	override, err := o.getHostOverride()
+// SwitchToIP implements gslb.Gslb.
+func (o *OpnSenseGslb) SwitchToIP(ctx context.Context, ip string) error {
+	if o.cfg.MultiValue {
+		return o.SetIPs(ctx, []string{ip})
+	}
+
+	return o.updateHostOverride(ctx, "1", ip)
+}
+
+// updateHostOverride sets the enabled flag and server IP of the record,
+// keeping the current IP if ip is empty.
+func (o *OpnSenseGslb) updateHostOverride(ctx context.Context, enabled, ip string) error {
+	override, err := o.getHostOverride(ctx, o.recordUUID)
 	if err != nil {
 		return fmt.Errorf("getting host override: %w", err)
 	}
@@ -275,9 +365,28 @@ func (o *OpnSenseGslb) switchToIP(ip string) error {
 		return fmt.Errorf("host override record has no A or AAAA record selected")
 	}
 
-	// Prepare setHostOverride request payload
+	if ip == "" {
+		ip = override.Host.Server
+	}
+
+	if err := o.setHostOverride(ctx, o.recordUUID, override, enabled, rr, ip); err != nil {
+		return err
+	}
+
+	// Restart Unbound service to apply changes
+	err = o.restartUnboundService(ctx)
+	if err != nil {
+		return fmt.Errorf("restarting Unbound service: %w", err)
+	}
+
+	return nil
+}
+
+// hostOverridePayload copies the settings of override, changing the enabled
+// flag, resource record type and server IP.
+func hostOverridePayload(override *unboundGetHostOverrideResponse, enabled, rr, ip string) ([]byte, error) {
 	reqPayload := &unboundSetHostOverrideRequest{}
-	reqPayload.Host.Enabled = override.Host.Enabled
+	reqPayload.Host.Enabled = enabled
 	reqPayload.Host.Hostname = override.Host.Hostname
 	reqPayload.Host.Domain = override.Host.Domain
 	reqPayload.Host.RR = rr
@@ -289,11 +398,21 @@ func (o *OpnSenseGslb) switchToIP(ip string) error {
 
 	payload, err := json.Marshal(reqPayload)
 	if err != nil {
-		return fmt.Errorf("marshaling setHostOverride request payload: %w", err)
+		return nil, fmt.Errorf("marshaling host override request payload: %w", err)
+	}
+
+	return payload, nil
+}
+
+// setHostOverride updates the record uuid, without applying the change.
+func (o *OpnSenseGslb) setHostOverride(ctx context.Context, uuid string, override *unboundGetHostOverrideResponse, enabled, rr, ip string) error {
+	payload, err := hostOverridePayload(override, enabled, rr, ip)
+	if err != nil {
+		return err
 	}
 
 	// Send setHostOverride request
-	resp, err := o.doRequest(http.MethodPost, "/api/unbound/settings/setHostOverride/"+o.recordUUID, payload)
+	resp, err := o.doRequest(ctx, http.MethodPost, "/api/unbound/settings/setHostOverride/"+uuid, payload)
 	if err != nil {
 		return fmt.Errorf("setHostOverride request: %w", err)
 	}
@@ -313,12 +432,6 @@ func (o *OpnSenseGslb) switchToIP(ip string) error {
 		return fmt.Errorf("setHostOverride request failed: unexpected result %s", setResp.Result)
 	}
 
-	// Restart Unbound service to apply changes
-	err = o.restartUnboundService()
-	if err != nil {
-		return fmt.Errorf("restarting Unbound service: %w", err)
-	}
-
 	return nil
 }
 
@@ -326,8 +439,8 @@ type unboundServiceResponse struct {
 	Status string `json:"status"`
 }
 
-func (o *OpnSenseGslb) restartUnboundService() error {
-	resp, err := o.doRequest(http.MethodPost, "/api/unbound/service/reconfigure", nil)
+func (o *OpnSenseGslb) restartUnboundService(ctx context.Context) error {
+	resp, err := o.doRequest(ctx, http.MethodPost, "/api/unbound/service/reconfigure", nil)
 	if err != nil {
 		return fmt.Errorf("reconfigure request: %w", err)
 	}
@@ -350,12 +463,11 @@ func (o *OpnSenseGslb) restartUnboundService() error {
 	return nil
 }
 
-// SwitchToPrimaryIP implements gslb.Gslb.
-func (o *OpnSenseGslb) SwitchToPrimaryIP() error {
-	return o.switchToIP(o.PrimaryIP())
-}
+// DisableRecord implements gslb.Gslb.
+func (o *OpnSenseGslb) DisableRecord(ctx context.Context) error {
+	if o.cfg.MultiValue {
+		return o.SetIPs(ctx, nil)
+	}
 
-// SwitchToSecondaryIP implements gslb.Gslb.
-func (o *OpnSenseGslb) SwitchToSecondaryIP() error {
-	return o.switchToIP(o.SecondaryIP())
+	return o.updateHostOverride(ctx, "0", "")
 }
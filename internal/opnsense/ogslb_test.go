@@ -1,11 +1,14 @@
 package opnsense
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/microfast-ch/gslb-switcher/internal/gslb"
 )
@@ -15,12 +18,7 @@ func TestNewOpnSenseGslb_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/api/unbound/settings/searchHostOverride/" {
 			response := unboundSearchHostOverrideResponse{
-				Rows: []struct {
-					UUID           string `json:"uuid"`
-					Hostname       string `json:"hostname"`
-					Domain         string `json:"domain"`
-					ResourceRecord string `json:"rr"`
-				}{
+				Rows: []unboundSearchHostOverrideRow{
 					{
 						UUID:           "test-uuid-123",
 						Hostname:       "test-host",
@@ -42,7 +40,7 @@ func TestNewOpnSenseGslb_Success(t *testing.T) {
 		SecondaryIP: "10.0.0.2",
 	}
 
-	gslbImpl, err := NewOpnSenseGslb(server.URL, "test-auth", cfg)
+	gslbImpl, err := NewOpnSenseGslb(context.Background(), server.URL, "test-auth", cfg)
 	if err != nil {
 		t.Fatalf("NewOpnSenseGslb failed: %v", err)
 	}
@@ -61,12 +59,7 @@ func TestNewOpnSenseGslb_MultipleRecordsError(t *testing.T) {
 	// Create a mock server that returns multiple matching records
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := unboundSearchHostOverrideResponse{
-			Rows: []struct {
-				UUID           string `json:"uuid"`
-				Hostname       string `json:"hostname"`
-				Domain         string `json:"domain"`
-				ResourceRecord string `json:"rr"`
-			}{
+			Rows: []unboundSearchHostOverrideRow{
 				{
 					UUID:           "uuid-1",
 					Hostname:       "test-host",
@@ -91,7 +84,7 @@ func TestNewOpnSenseGslb_MultipleRecordsError(t *testing.T) {
 		SecondaryIP: "10.0.0.2",
 	}
 
-	_, err := NewOpnSenseGslb(server.URL, "", cfg)
+	_, err := NewOpnSenseGslb(context.Background(), server.URL, "", cfg)
 	if err == nil {
 		t.Fatal("Expected error for multiple records, got nil")
 	}
@@ -105,12 +98,7 @@ func TestNewOpnSenseGslb_NoRecordsError(t *testing.T) {
 	// Create a mock server that returns no matching records
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := unboundSearchHostOverrideResponse{
-			Rows: []struct {
-				UUID           string `json:"uuid"`
-				Hostname       string `json:"hostname"`
-				Domain         string `json:"domain"`
-				ResourceRecord string `json:"rr"`
-			}{},
+			Rows: []unboundSearchHostOverrideRow{},
 		}
 		json.NewEncoder(w).Encode(resp) // nolint:errcheck
 	}))
@@ -122,7 +110,7 @@ func TestNewOpnSenseGslb_NoRecordsError(t *testing.T) {
 		SecondaryIP: "10.0.0.2",
 	}
 
-	_, err := NewOpnSenseGslb(server.URL, "", cfg)
+	_, err := NewOpnSenseGslb(context.Background(), server.URL, "", cfg)
 	if err == nil {
 		t.Fatal("Expected error for no records, got nil")
 	}
@@ -135,12 +123,7 @@ func TestNewOpnSenseGslb_NoRecordsError(t *testing.T) {
 func TestNewOpnSenseGslb_IgnoresNonARecords(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := unboundSearchHostOverrideResponse{
-			Rows: []struct {
-				UUID           string `json:"uuid"`
-				Hostname       string `json:"hostname"`
-				Domain         string `json:"domain"`
-				ResourceRecord string `json:"rr"`
-			}{
+			Rows: []unboundSearchHostOverrideRow{
 				{
 					UUID:           "mx-record",
 					Hostname:       "test-host",
@@ -165,7 +148,7 @@ func TestNewOpnSenseGslb_IgnoresNonARecords(t *testing.T) {
 		SecondaryIP: "10.0.0.2",
 	}
 
-	g, err := NewOpnSenseGslb(server.URL, "", cfg)
+	g, err := NewOpnSenseGslb(context.Background(), server.URL, "", cfg)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -184,12 +167,7 @@ func TestNewOpnSenseGslb_MatchesHostnameDomain(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Return a record where hostname.domain matches the search
 		resp := unboundSearchHostOverrideResponse{
-			Rows: []struct {
-				UUID           string `json:"uuid"`
-				Hostname       string `json:"hostname"`
-				Domain         string `json:"domain"`
-				ResourceRecord string `json:"rr"`
-			}{
+			Rows: []unboundSearchHostOverrideRow{
 				{
 					UUID:           "correct-uuid",
 					Hostname:       "test",
@@ -208,7 +186,7 @@ func TestNewOpnSenseGslb_MatchesHostnameDomain(t *testing.T) {
 		SecondaryIP: "10.0.0.2",
 	}
 
-	g, err := NewOpnSenseGslb(server.URL, "", cfg)
+	g, err := NewOpnSenseGslb(context.Background(), server.URL, "", cfg)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -223,7 +201,7 @@ func TestNewOpnSenseGslb_MatchesHostnameDomain(t *testing.T) {
 	}
 }
 
-func TestIPRecords(t *testing.T) {
+func TestTargets(t *testing.T) {
 	o := &OpnSenseGslb{
 		cfg: gslb.GslbConfig{
 			PrimaryIP:   "192.168.1.1",
@@ -231,12 +209,17 @@ func TestIPRecords(t *testing.T) {
 		},
 	}
 
-	if o.PrimaryIP() != "192.168.1.1" {
-		t.Errorf("Expected PrimaryIP to be '192.168.1.1', got '%s'", o.PrimaryIP())
+	targets := o.Targets()
+	if len(targets) != 2 {
+		t.Fatalf("Expected 2 targets, got %d", len(targets))
+	}
+
+	if targets[0].IP != "192.168.1.1" {
+		t.Errorf("Expected first target to be '192.168.1.1', got '%s'", targets[0].IP)
 	}
 
-	if o.SecondaryIP() != "192.168.1.2" {
-		t.Errorf("Expected SecondaryIP to be '192.168.1.2', got '%s'", o.SecondaryIP())
+	if targets[1].IP != "192.168.1.2" {
+		t.Errorf("Expected second target to be '192.168.1.2', got '%s'", targets[1].IP)
 	}
 }
 
@@ -287,7 +270,7 @@ func TestGetCurrentIP_ARecord(t *testing.T) {
 		recordUUID: "test-uuid",
 	}
 
-	ip, err := o.GetCurrentIP()
+	ip, err := o.GetCurrentIP(context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -337,7 +320,7 @@ func TestGetCurrentIP_AAAARecord(t *testing.T) {
 		recordUUID: "test-uuid",
 	}
 
-	ip, err := o.GetCurrentIP()
+	ip, err := o.GetCurrentIP(context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -388,7 +371,7 @@ func TestGetCurrentIP_NoRecordSelected(t *testing.T) {
 		recordUUID: "test-uuid",
 	}
 
-	_, err := o.GetCurrentIP()
+	_, err := o.GetCurrentIP(context.Background())
 	if err == nil {
 		t.Fatal("Expected error for no record selected, got nil")
 	}
@@ -437,7 +420,7 @@ func TestGetCurrentIP_EmptyServerWithARecord(t *testing.T) {
 		recordUUID: "test-uuid",
 	}
 
-	_, err := o.GetCurrentIP()
+	_, err := o.GetCurrentIP(context.Background())
 	if err == nil {
 		t.Fatal("Expected error for empty server IP, got nil")
 	}
@@ -447,7 +430,7 @@ func TestGetCurrentIP_EmptyServerWithARecord(t *testing.T) {
 	}
 }
 
-func TestSwitchToPrimaryIP(t *testing.T) {
+func TestSwitchToIP(t *testing.T) {
 	callCount := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		callCount++
@@ -530,96 +513,11 @@ func TestSwitchToPrimaryIP(t *testing.T) {
 	defer server.Close()
 
 	o := &OpnSenseGslb{
-		cfg: gslb.GslbConfig{
-			PrimaryIP: "10.0.0.1",
-		},
 		epHost:     server.URL,
 		recordUUID: "test-uuid",
 	}
 
-	err := o.SwitchToPrimaryIP()
-	if err != nil {
-		t.Fatalf("Expected no error, got: %v", err)
-	}
-
-	if callCount != 3 {
-		t.Errorf("Expected 3 API calls, got %d", callCount)
-	}
-}
-
-func TestSwitchToSecondaryIP(t *testing.T) {
-	callCount := 0
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		callCount++
-
-		switch callCount {
-		case 1:
-			// First call: getHostOverride
-			resp := unboundGetHostOverrideResponse{
-				Host: struct {
-					Enabled  string `json:"enabled"`
-					Hostname string `json:"hostname"`
-					Domain   string `json:"domain"`
-					RR       struct {
-						A struct {
-							Value    string `json:"value"`
-							Selected int    `json:"selected"`
-						} `json:"A"`
-						AAAA struct {
-							Value    string `json:"value"`
-							Selected int    `json:"selected"`
-						} `json:"AAAA"`
-					} `json:"rr"`
-					MXPrio      string `json:"mxprio"`
-					MX          string `json:"mx"`
-					TTL         string `json:"ttl"`
-					Server      string `json:"server"`
-					Description string `json:"description"`
-				}{
-					Enabled:     "1",
-					Hostname:    "test",
-					Domain:      "local",
-					Server:      "10.0.0.1",
-					TTL:         "300",
-					Description: "Test record",
-				},
-			}
-			resp.Host.RR.A.Selected = 1
-			json.NewEncoder(w).Encode(resp) // nolint:errcheck
-		case 2:
-			// Second call: setHostOverride
-			var req unboundSetHostOverrideRequest
-			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-				t.Fatalf("Failed to decode request: %v", err)
-			}
-
-			if req.Host.Server != "10.0.0.2" {
-				t.Errorf("Expected server to be '10.0.0.2', got '%s'", req.Host.Server)
-			}
-
-			resp := unboundSetHostOverrideResponse{
-				Result: "saved",
-			}
-			json.NewEncoder(w).Encode(resp) // nolint:errcheck
-		case 3:
-			// Third call: reconfigure service
-			resp := unboundServiceResponse{
-				Status: "ok",
-			}
-			json.NewEncoder(w).Encode(resp) // nolint:errcheck
-		}
-	}))
-	defer server.Close()
-
-	o := &OpnSenseGslb{
-		cfg: gslb.GslbConfig{
-			SecondaryIP: "10.0.0.2",
-		},
-		epHost:     server.URL,
-		recordUUID: "test-uuid",
-	}
-
-	err := o.SwitchToSecondaryIP()
+	err := o.SwitchToIP(context.Background(), "10.0.0.1")
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -677,14 +575,11 @@ func TestSwitchToIP_SetHostOverrideFailure(t *testing.T) {
 	defer server.Close()
 
 	o := &OpnSenseGslb{
-		cfg: gslb.GslbConfig{
-			PrimaryIP: "10.0.0.1",
-		},
 		epHost:     server.URL,
 		recordUUID: "test-uuid",
 	}
 
-	err := o.SwitchToPrimaryIP()
+	err := o.SwitchToIP(context.Background(), "10.0.0.1")
 	if err == nil {
 		t.Fatal("Expected error for setHostOverride failure, got nil")
 	}
@@ -748,14 +643,11 @@ func TestSwitchToIP_ReconfigureFailure(t *testing.T) {
 	defer server.Close()
 
 	o := &OpnSenseGslb{
-		cfg: gslb.GslbConfig{
-			PrimaryIP: "10.0.0.1",
-		},
 		epHost:     server.URL,
 		recordUUID: "test-uuid",
 	}
 
-	err := o.SwitchToPrimaryIP()
+	err := o.SwitchToIP(context.Background(), "10.0.0.1")
 	if err == nil {
 		t.Fatal("Expected error for reconfigure failure, got nil")
 	}
@@ -834,14 +726,11 @@ func TestSwitchToIP_AAAARecord(t *testing.T) {
 	defer server.Close()
 
 	o := &OpnSenseGslb{
-		cfg: gslb.GslbConfig{
-			PrimaryIP: "2001:db8::1",
-		},
 		epHost:     server.URL,
 		recordUUID: "test-uuid",
 	}
 
-	err := o.SwitchToPrimaryIP()
+	err := o.SwitchToIP(context.Background(), "2001:db8::1")
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -850,3 +739,96 @@ func TestSwitchToIP_AAAARecord(t *testing.T) {
 		t.Errorf("Expected 3 API calls, got %d", callCount)
 	}
 }
+
+func TestNewOpnSenseGslb_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Simulate a slow API
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := NewOpnSenseGslb(ctx, server.URL, "", gslb.GslbConfig{Host: "test-host"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context deadline error, got: %v", err)
+	}
+}
+
+func TestGetCurrentIP_Disabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"host":{"enabled":"0","hostname":"test","domain":"local","server":"10.0.0.1","rr":{"A":{"selected":1}}}}`)) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	o := &OpnSenseGslb{
+		epHost:     server.URL,
+		recordUUID: "test-uuid",
+	}
+
+	ip, err := o.GetCurrentIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if ip != "" {
+		t.Errorf("Expected no IP for disabled record, got '%s'", ip)
+	}
+}
+
+func TestDisableRecord(t *testing.T) {
+	var enabled []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/getHostOverride/"):
+			w.Write([]byte(`{"host":{"enabled":"1","hostname":"test","domain":"local","server":"10.0.0.1","rr":{"A":{"selected":1}}}}`)) // nolint:errcheck
+		case strings.Contains(r.URL.Path, "/setHostOverride/"):
+			var req unboundSetHostOverrideRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("Failed to decode request: %v", err)
+			}
+
+			if req.Host.Server != "10.0.0.1" {
+				t.Errorf("Expected server to stay '10.0.0.1', got '%s'", req.Host.Server)
+			}
+
+			enabled = append(enabled, req.Host.Enabled)
+			w.Write([]byte(`{"result":"saved"}`)) // nolint:errcheck
+		default:
+			w.Write([]byte(`{"status":"ok"}`)) // nolint:errcheck
+		}
+	}))
+	defer server.Close()
+
+	o := &OpnSenseGslb{
+		epHost:     server.URL,
+		recordUUID: "test-uuid",
+	}
+
+	if err := o.DisableRecord(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Switching enables the record again
+	if err := o.SwitchToIP(context.Background(), "10.0.0.1"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if strings.Join(enabled, ",") != "0,1" {
+		t.Errorf("Expected record to be disabled and enabled, got %v", enabled)
+	}
+}
+
+func TestCheckHealth_NotConfigured(t *testing.T) {
+	o := &OpnSenseGslb{}
+
+	res, err := o.CheckHealth(context.Background(), gslb.Target{Name: "secondary", IP: "10.0.0.2"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if !res.Healthy() {
+		t.Errorf("Expected unchecked target to be healthy, got %s", res.State)
+	}
+}
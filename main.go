@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
-	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -14,32 +17,51 @@ import (
 	"github.com/microfast-ch/gslb-switcher/internal/opnsense"
 )
 
+// evalInterval is the time between two evaluations of the targets.
+const evalInterval = 60 * time.Second
+
 func main() {
 	// Global Configuration
 	gslbHost := os.Getenv("GSLB_HOST")
-	gslbPrimary := os.Getenv("GSLB_PRIMARY_IP")
-	gslbPrimaryCheck := os.Getenv("GSLB_PRIMARY_CHECK")
-	gslbSecondary := os.Getenv("GSLB_SECONDARY_IP")
-	gslbSkipTLSVerify, _ := strconv.ParseBool(os.Getenv("GSLB_PRIMARY_CHECK_SKIP_TLS_VERIFY"))
 
-	if gslbHost == "" || gslbPrimary == "" || gslbPrimaryCheck == "" || gslbSecondary == "" {
+	if gslbHost == "" {
 		slog.Error("missing required environment variables",
 			slog.String("GSLB_HOST", gslbHost),
-			slog.String("GSLB_PRIMARY_IP", gslbPrimary),
-			slog.String("GSLB_PRIMARY_CHECK", gslbPrimaryCheck),
-			slog.String("GSLB_SECONDARY_IP", gslbSecondary),
 		)
 		os.Exit(1)
 	}
 
-	// Create checker, currently only SimpleHTTPChecker is supported
-	chk := checkers.NewSimpleHTTPChecker(gslbPrimaryCheck, gslbSkipTLSVerify)
+	// Optional receiver for health pushed by the monitoring stack
+	webhookAddr := os.Getenv("GSLB_WEBHOOK_LISTEN")
+
+	var webhook *checkers.WebhookReceiver
+	if webhookAddr != "" {
+		var err error
+		if webhook, err = newWebhookReceiver(); err != nil {
+			slog.Error("error creating health webhook", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	// Targets with their checkers, in order of priority
+	targets, err := envTargets(gslbHost, webhook)
+	if err != nil {
+		slog.Error("error configuring targets", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Hysteresis and flap damping of the switching decision
+	policy, err := envSwitchPolicy()
+	if err != nil {
+		slog.Error("error parsing switch policy", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
 
+	// Active-active mode needs a record per healthy target
 	cfg := gslb.GslbConfig{
-		Host:                 gslbHost,
-		PrimaryIP:            gslbPrimary,
-		SecondaryIP:          gslbSecondary,
-		PrimaryHealthChecker: chk,
+		Host:       gslbHost,
+		Targets:    targets,
+		MultiValue: policy.Mode == gslb.ModeActiveActive,
 	}
 
 	// We currently only support OpnSense as GSLB provider
@@ -54,13 +76,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	p, err := opnsense.NewOpnSenseGslb(opnsenseHost, opnsenseAuth, cfg)
-	if err != nil {
-		slog.Error("error creating GSLB provider", slog.String("error", err.Error()))
-		os.Exit(1)
-	}
-
-	// Start GSLB
+	// Cancel on shutdown, so pending checks and API calls are aborted
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -73,8 +89,136 @@ func main() {
 		cancel()
 	}()
 
-	if err := gslb.Run(ctx, p, 60*time.Second); err != nil && err != context.Canceled {
+	if webhook != nil {
+		// Bind before checking, so webhook checks never wait for pushes
+		// that cannot arrive
+		ln, err := net.Listen("tcp", webhookAddr)
+		if err != nil {
+			slog.Error("error listening for health webhook", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+
+		go func() {
+			if err := serveWebhook(ctx, ln, webhook); err != nil {
+				slog.Error("error serving health webhook", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+		}()
+	}
+
+	p, err := opnsense.NewOpnSenseGslb(ctx, opnsenseHost, opnsenseAuth, cfg)
+	if err != nil {
+		slog.Error("error creating GSLB provider", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Start GSLB
+	if err := gslb.Run(ctx, p, evalInterval, policy); err != nil && err != context.Canceled {
 		slog.Error("error running GSLB", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 }
+
+// envTargets reads the targets of the record from the environment. Targets
+// listed in GSLB_TARGETS are configured with the prefix GSLB_TARGET_<NAME>,
+// without it the primary and secondary are the targets.
+func envTargets(host string, webhook *checkers.WebhookReceiver) ([]gslb.Target, error) {
+	names := envList("GSLB_TARGETS")
+	if len(names) == 0 {
+		return envPrimarySecondary(host, webhook)
+	}
+
+	var targets []gslb.Target
+	for i, name := range names {
+		prefix := "GSLB_TARGET_" + strings.ToUpper(name)
+
+		t := gslb.Target{Name: name, IP: os.Getenv(prefix + "_IP")}
+		if t.IP == "" {
+			return nil, fmt.Errorf("missing %s_IP", prefix)
+		}
+
+		// Like the secondary, the last target may go unchecked
+		if os.Getenv(prefix+"_CHECK") != "" {
+			chk, err := newHealthChecker(prefix+"_CHECK", host, webhook)
+			if err != nil {
+				return nil, fmt.Errorf("creating %s health checker: %w", name, err)
+			}
+
+			t.HealthChecker = chk
+		} else if i < len(names)-1 {
+			return nil, fmt.Errorf("missing %s_CHECK", prefix)
+		}
+
+		targets = append(targets, t)
+	}
+
+	return targets, nil
+}
+
+// envPrimarySecondary reads the primary and the secondary target from the
+// environment.
+func envPrimarySecondary(host string, webhook *checkers.WebhookReceiver) ([]gslb.Target, error) {
+	primary := gslb.Target{Name: "primary", IP: os.Getenv("GSLB_PRIMARY_IP")}
+	secondary := gslb.Target{Name: "secondary", IP: os.Getenv("GSLB_SECONDARY_IP")}
+
+	if primary.IP == "" || os.Getenv("GSLB_PRIMARY_CHECK") == "" || secondary.IP == "" {
+		return nil, errors.New("missing GSLB_TARGETS or GSLB_PRIMARY_IP, GSLB_PRIMARY_CHECK and GSLB_SECONDARY_IP")
+	}
+
+	// Checker selected by GSLB_PRIMARY_CHECK_TYPE
+	chk, err := newHealthChecker("GSLB_PRIMARY_CHECK", host, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("creating primary health checker: %w", err)
+	}
+
+	primary.HealthChecker = chk
+
+	// Optional checker of the secondary, selected by GSLB_SECONDARY_CHECK_TYPE
+	if os.Getenv("GSLB_SECONDARY_CHECK") != "" {
+		chk, err := newHealthChecker("GSLB_SECONDARY_CHECK", host, webhook)
+		if err != nil {
+			return nil, fmt.Errorf("creating secondary health checker: %w", err)
+		}
+
+		secondary.HealthChecker = chk
+	}
+
+	return []gslb.Target{primary, secondary}, nil
+}
+
+// envSwitchPolicy reads when the GSLB record is switched from the
+// environment.
+func envSwitchPolicy() (gslb.SwitchPolicy, error) {
+	var p gslb.SwitchPolicy
+	var err error
+
+	if p.Mode, err = gslb.ParseMode(os.Getenv("GSLB_MODE")); err != nil {
+		return p, err
+	}
+	if p.Rise, err = envInt("GSLB_RISE", 1); err != nil {
+		return p, err
+	}
+	if p.Fall, err = envInt("GSLB_FALL", 1); err != nil {
+		return p, err
+	}
+	if p.MinDwell, err = envDuration("GSLB_MIN_DWELL", 0); err != nil {
+		return p, err
+	}
+	if p.Damping.MaxFlaps, err = envInt("GSLB_DAMPING_MAX_FLAPS", 0); err != nil {
+		return p, err
+	}
+	if p.Damping.HalfLife, err = envDuration("GSLB_DAMPING_HALF_LIFE", 0); err != nil {
+		return p, err
+	}
+	if p.Damping.MaxSuppress, err = envDuration("GSLB_DAMPING_MAX_SUPPRESS", 0); err != nil {
+		return p, err
+	}
+	if err := p.Damping.Validate(); err != nil {
+		return p, fmt.Errorf("invalid GSLB_DAMPING_MAX_SUPPRESS: %w", err)
+	}
+	if p.BothDown, err = gslb.ParseBothDownPolicy(os.Getenv("GSLB_BOTH_DOWN")); err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/microfast-ch/gslb-switcher/internal/checkers"
+)
+
+// newWebhookReceiver creates the receiver for pushed health configured by the
+// GSLB_WEBHOOK_* environment variables.
+func newWebhookReceiver() (*checkers.WebhookReceiver, error) {
+	r := checkers.NewWebhookReceiver()
+	r.HostLabel = os.Getenv("GSLB_WEBHOOK_HOST_LABEL")
+
+	var err error
+	if r.TTL, err = envDuration("GSLB_WEBHOOK_TTL", 0); err != nil {
+		return nil, err
+	}
+	if r.AlertTTL, err = envDuration("GSLB_WEBHOOK_ALERT_TTL", 0); err != nil {
+		return nil, err
+	}
+
+	if tokenFile := os.Getenv("GSLB_WEBHOOK_TOKEN_FILE"); tokenFile != "" {
+		token, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading GSLB_WEBHOOK_TOKEN_FILE: %w", err)
+		}
+
+		r.Token = strings.TrimSpace(string(token))
+	}
+
+	return r, nil
+}
+
+// serveWebhook serves the receiver on ln until ctx is done.
+func serveWebhook(ctx context.Context, ln net.Listener, r *checkers.WebhookReceiver) error {
+	srv := &http.Server{
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		srv.Shutdown(shutdownCtx) //nolint:errcheck
+	}()
+
+	slog.Info("receiving pushed health", slog.String("addr", ln.Addr().String()))
+
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}